@@ -0,0 +1,272 @@
+package mdp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+/*
+The binary MDP format lets SolveFile solve MDPs with more transitions than
+fit in memory.  All numbers are little-endian.  The file starts with the
+magic bytes "mdp1" and the number of states as a uint64.  Then come the
+states in order, each as
+
+	player   uint8
+	reward   float64
+	actions  uint32
+	actions times: next state uint64, probability float64
+
+A value file, as used for checkpoints, is the magic bytes "val1", the number
+of states as a uint64, and the value of each state as a float64.
+*/
+
+var ErrBadFile = errors.New("mdp: bad file format")
+
+const (
+	mdpMagic   = "mdp1"
+	valueMagic = "val1"
+)
+
+// FileWriter writes an MDP in the binary format one state at a time, so that
+// the whole MDP never needs to be in memory.
+type FileWriter struct {
+	w    *bufio.Writer
+	left uint64
+	buf  [16]byte
+}
+
+// NewFileWriter returns a FileWriter that writes an MDP with numStates
+// states to w.
+func NewFileWriter(w io.Writer, numStates uint64) (*FileWriter, error) {
+	fw := &FileWriter{w: bufio.NewWriter(w), left: numStates}
+	fw.w.WriteString(mdpMagic)
+	binary.LittleEndian.PutUint64(fw.buf[:], numStates)
+	_, err := fw.w.Write(fw.buf[:8])
+	return fw, err
+}
+
+// Write writes the next state.
+func (fw *FileWriter) Write(state State) error {
+	if fw.left == 0 {
+		return errors.New("mdp: too many states written")
+	}
+	fw.left--
+	fw.buf[0] = byte(state.Player)
+	binary.LittleEndian.PutUint64(fw.buf[1:], math.Float64bits(float64(state.Reward)))
+	binary.LittleEndian.PutUint32(fw.buf[9:], uint32(len(state.Action)))
+	fw.w.Write(fw.buf[:13])
+	for _, a := range state.Action {
+		binary.LittleEndian.PutUint64(fw.buf[:], uint64(a.NextState))
+		binary.LittleEndian.PutUint64(fw.buf[8:], math.Float64bits(a.Prob))
+		if _, err := fw.w.Write(fw.buf[:16]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close flushes the output.  It is an error to write fewer states than
+// promised to NewFileWriter.
+func (fw *FileWriter) Close() error {
+	if fw.left != 0 {
+		return fmt.Errorf("mdp: %d states not written", fw.left)
+	}
+	return fw.w.Flush()
+}
+
+// WriteFile writes the MDP to w in the binary format.
+func (states MDP) WriteFile(w io.Writer) error {
+	fw, err := NewFileWriter(w, uint64(len(states)))
+	if err != nil {
+		return err
+	}
+	for _, state := range states {
+		if err := fw.Write(state); err != nil {
+			return err
+		}
+	}
+	return fw.Close()
+}
+
+// fileReader reads the states of a binary MDP file in order.
+type fileReader struct {
+	f         *os.File
+	r         *bufio.Reader
+	numStates uint64
+	buf       [16]byte
+}
+
+func openFile(path string) (*fileReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	fr := &fileReader{f: f, r: bufio.NewReaderSize(f, 1<<20)}
+	if _, err := io.ReadFull(fr.r, fr.buf[:12]); err != nil || string(fr.buf[:4]) != mdpMagic {
+		f.Close()
+		return nil, ErrBadFile
+	}
+	fr.numStates = binary.LittleEndian.Uint64(fr.buf[4:])
+	// Each state takes at least 13 bytes, so a corrupt count can't make
+	// SolveFile allocate more than the file holds.
+	if info, err := f.Stat(); err != nil || fr.numStates > uint64(info.Size()-12)/13 {
+		f.Close()
+		return nil, ErrBadFile
+	}
+	return fr, nil
+}
+
+// next reads the next state, reusing the actions slice of state.  If
+// skipActions is set, it doesn't read the state's actions.
+func (fr *fileReader) next(state *State, skipActions bool) error {
+	if _, err := io.ReadFull(fr.r, fr.buf[:13]); err != nil {
+		return ErrBadFile
+	}
+	state.Player = Player(fr.buf[0])
+	state.Reward = Value(math.Float64frombits(binary.LittleEndian.Uint64(fr.buf[1:])))
+	n := int(binary.LittleEndian.Uint32(fr.buf[9:]))
+	state.Action = state.Action[:0]
+	if skipActions {
+		if _, err := fr.r.Discard(16 * n); err != nil {
+			return ErrBadFile
+		}
+		return nil
+	}
+	for i := 0; i < n; i++ {
+		if _, err := io.ReadFull(fr.r, fr.buf[:16]); err != nil {
+			return ErrBadFile
+		}
+		next := binary.LittleEndian.Uint64(fr.buf[:])
+		if next >= fr.numStates {
+			return ErrBadFile
+		}
+		prob := math.Float64frombits(binary.LittleEndian.Uint64(fr.buf[8:]))
+		state.Action = append(state.Action, Action{uint(next), prob})
+	}
+	return nil
+}
+
+// SolveFile is like Solve, but streams the states and actions of the MDP
+// from a file in the binary format on each iteration, keeping only the
+// rewards and two arrays of values in memory.  If checkpoint is not empty,
+// the values are saved there after each iteration, and if the checkpoint
+// file already exists, iteration resumes from the values in it.
+func SolveFile(path string, discount, tolerance float64, checkpoint string, progress func(Stats)) ([]Value, Stats, error) {
+	fr, err := openFile(path)
+	if err != nil {
+		return nil, Stats{}, err
+	}
+	defer fr.f.Close()
+	n := fr.numStates
+	// Read the rewards, which the Bellman update needs for every next state.
+	R := make([]Value, n)
+	state := State{}
+	for i := range R {
+		if err := fr.next(&state, true); err != nil {
+			return nil, Stats{}, err
+		}
+		R[i] = state.Reward
+	}
+	V0 := make([]Value, n)
+	if checkpoint != "" {
+		V, err := readValueFile(checkpoint)
+		switch {
+		case err == nil && uint64(len(V)) != n:
+			return nil, Stats{}, fmt.Errorf("mdp: checkpoint %s has %d values; want %d", checkpoint, len(V), n)
+		case err == nil:
+			V0 = V
+		case !os.IsNotExist(err):
+			return nil, Stats{}, err
+		}
+	}
+	γ := Value(discount)
+	reward := func(s uint) Value { return R[s] }
+	return sweepUntil(V0, tolerance, progress, func(prev, cur []Value) error {
+		if _, err := fr.f.Seek(12, io.SeekStart); err != nil {
+			return err
+		}
+		fr.r.Reset(fr.f)
+		for i := range cur {
+			if err := fr.next(&state, false); err != nil {
+				return err
+			}
+			if len(state.Action) > 0 {
+				cur[i] = bellman(state, γ, reward, prev)
+			}
+		}
+		if checkpoint != "" {
+			return writeValueFile(checkpoint, cur)
+		}
+		return nil
+	})
+}
+
+// WriteValues writes values to w in the value file format.
+func WriteValues(w io.Writer, V []Value) error {
+	bw := bufio.NewWriter(w)
+	var buf [8]byte
+	bw.WriteString(valueMagic)
+	binary.LittleEndian.PutUint64(buf[:], uint64(len(V)))
+	bw.Write(buf[:])
+	for _, v := range V {
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(float64(v)))
+		bw.Write(buf[:])
+	}
+	return bw.Flush()
+}
+
+// ReadValues reads values in the value file format from r.
+func ReadValues(r io.Reader) ([]Value, error) {
+	br := bufio.NewReader(r)
+	var buf [12]byte
+	if _, err := io.ReadFull(br, buf[:]); err != nil || string(buf[:4]) != valueMagic {
+		return nil, ErrBadFile
+	}
+	n := binary.LittleEndian.Uint64(buf[4:])
+	// Grow V as the values arrive, so that a corrupt count can't allocate
+	// more than r holds.
+	size := n
+	if size > 1<<16 {
+		size = 1 << 16
+	}
+	V := make([]Value, 0, size)
+	for uint64(len(V)) < n {
+		if _, err := io.ReadFull(br, buf[:8]); err != nil {
+			return nil, ErrBadFile
+		}
+		V = append(V, Value(math.Float64frombits(binary.LittleEndian.Uint64(buf[:8]))))
+	}
+	return V, nil
+}
+
+func readValueFile(path string) ([]Value, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadValues(f)
+}
+
+// writeValueFile replaces the file at path with values V, so that a crash
+// never leaves a partial checkpoint.
+func writeValueFile(path string, V []Value) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := WriteValues(f, V); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
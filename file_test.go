@@ -0,0 +1,115 @@
+package mdp
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSolveFile(t *testing.T) {
+	tolerance := 1e-12
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bus.mdp")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := busTicket.WriteFile(f); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	want, wantStats := busTicket.Solve(1.0, tolerance, nil)
+	got, stats, err := SolveFile(path, 1.0, tolerance, "", nil)
+	if err != nil {
+		t.Fatalf("SolveFile(): %v", err)
+	}
+	if stats.Iterations != wantStats.Iterations {
+		t.Errorf("SolveFile() took %d iterations; want %d", stats.Iterations, wantStats.Iterations)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("SolveFile() V[%d]: got %v; want %v", i, got[i], want[i])
+		}
+	}
+
+	// Resuming from converged values takes a single iteration.
+	checkpoint := filepath.Join(dir, "bus.val")
+	if _, _, err := SolveFile(path, 1.0, tolerance, checkpoint, nil); err != nil {
+		t.Fatalf("SolveFile(checkpoint): %v", err)
+	}
+	got, stats, err = SolveFile(path, 1.0, tolerance, checkpoint, nil)
+	if err != nil {
+		t.Fatalf("SolveFile(checkpoint): %v", err)
+	}
+	if stats.Iterations != 1 || math.Abs(float64(got[2]-want[2])) > tolerance {
+		t.Errorf("SolveFile(resumed)=%v after %d iterations; want %v after 1", got, stats.Iterations, want)
+	}
+}
+
+func TestValuesRoundTrip(t *testing.T) {
+	V := []Value{0, 1.5, -2, Value(math.Inf(1))}
+	var buf bytes.Buffer
+	if err := WriteValues(&buf, V); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadValues(&buf)
+	if err != nil {
+		t.Fatalf("ReadValues(): %v", err)
+	}
+	if len(got) != len(V) {
+		t.Fatalf("ReadValues()=%v; want %v", got, V)
+	}
+	for i := range V {
+		if got[i] != V[i] {
+			t.Errorf("ReadValues()=%v; want %v", got, V)
+		}
+	}
+	for _, bad := range []string{
+		"mdp1",
+		"val1\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00",
+		"val1\xff\xff\xff\xff\xff\xff\xff\x7f",
+		"val1\x00\x00\x00\x00\x00\x01\x00\x00",
+	} {
+		if _, err := ReadValues(bytes.NewReader([]byte(bad))); err != ErrBadFile {
+			t.Errorf("ReadValues(%q)=%v; want %v", bad, err, ErrBadFile)
+		}
+	}
+}
+
+func TestSolveFileBadHeader(t *testing.T) {
+	dir := t.TempDir()
+	for i, bad := range []string{
+		"mdp1\x00",
+		"mdp1\xff\xff\xff\xff\xff\xff\xff\x7f",
+		// Two states, but only room for one.
+		"mdp1\x02\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00",
+	} {
+		path := filepath.Join(dir, fmt.Sprintf("bad%d.mdp", i))
+		if err := ioutil.WriteFile(path, []byte(bad), 0666); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := SolveFile(path, 1.0, 1e-12, "", nil); err != ErrBadFile {
+			t.Errorf("SolveFile(%q)=%v; want %v", bad, err, ErrBadFile)
+		}
+	}
+	// A corrupt checkpoint is an error, too.
+	path := filepath.Join(dir, "bus.mdp")
+	var buf bytes.Buffer
+	if err := busTicket.WriteFile(&buf); err != nil {
+		t.Fatal(err)
+	}
+	checkpoint := filepath.Join(dir, "bus.val")
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(checkpoint, []byte("val1\xff\xff\xff\xff\xff\xff\xff\x7f"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := SolveFile(path, 1.0, 1e-12, checkpoint, nil); err != ErrBadFile {
+		t.Errorf("SolveFile(corrupt checkpoint)=%v; want %v", err, ErrBadFile)
+	}
+}
@@ -6,6 +6,7 @@ package mdp
 
 import (
 	"math"
+	"time"
 )
 
 /*
@@ -46,6 +47,14 @@ type State struct {
 	Action []Action
 }
 
+// Stats describes the progress of value iteration.
+type Stats struct {
+	Iterations int
+	Residual   float64       // Largest change to a value in the last iteration.
+	Elapsed    time.Duration // Wall time since the start.
+	Residuals  []float64     // Residual of each iteration.
+}
+
 // Value Iteration
 // http://www.cs.berkeley.edu/~pabbeel/cs287-fa12/slides/mdps-exact-methods.pdf
 // The returned array of values represents the value of each state, assuming
@@ -56,42 +65,86 @@ type State struct {
 // of ending the iteration process.
 // The algorithm is a kind of expectimax with loops.
 func (states MDP) Values(discount, tolerance float64) []Value {
+	V, _ := states.Solve(discount, tolerance, nil)
+	return V
+}
+
+// Solve is like Values, but also returns statistics about the iteration.  If
+// progress is not nil, it is called after each iteration.
+func (states MDP) Solve(discount, tolerance float64, progress func(Stats)) ([]Value, Stats) {
 	γ := Value(discount)
-	V := [][]Value{
-		make([]Value, len(states)),
-		make([]Value, len(states)),
+	reward := func(s uint) Value { return states[s].Reward }
+	return iterate(len(states), tolerance, progress, func(i int, V []Value) (Value, bool) {
+		if len(states[i].Action) == 0 {
+			return 0, false
+		}
+		return bellman(states[i], γ, reward, V), true
+	})
+}
+
+// bellman returns the new value of a state with actions, given the reward and
+// previous value of every state.
+func bellman(state State, γ Value, reward func(s uint) Value, V []Value) Value {
+	switch state.Player {
+	case Nature:
+		ev := Value(0.0) // Expected value of reward for next state.
+		for _, action := range state.Action {
+			s := action.NextState
+			ev += Value(action.Prob) * (reward(s) + γ*V[s])
+		}
+		return ev
+	case Player1:
+		max := negInf // Maximum value of reward for next state.
+		for _, action := range state.Action {
+			s := action.NextState
+			max = math.Max(max, float64(reward(s)+γ*V[s]))
+		}
+		return Value(max)
 	}
+	return 0
+}
+
+// iterate runs value iteration over n states until no value changes by more
+// than tolerance.  backup returns the new value of state i given the previous
+// values V, or false if state i has no actions.
+func iterate(n int, tolerance float64, progress func(Stats), backup func(i int, V []Value) (Value, bool)) ([]Value, Stats) {
+	V, stats, _ := sweepUntil(make([]Value, n), tolerance, progress, func(prev, cur []Value) error {
+		for i := range prev {
+			if v, ok := backup(i, prev); ok {
+				cur[i] = v
+			}
+		}
+		return nil
+	})
+	return V, stats
+}
+
+// sweepUntil starts with values V0 and repeatedly calls sweep to compute new
+// values cur from the previous values prev, until no value changes by more
+// than tolerance or sweep fails.
+func sweepUntil(V0 []Value, tolerance float64, progress func(Stats), sweep func(prev, cur []Value) error) ([]Value, Stats, error) {
+	start := time.Now()
+	stats := Stats{}
+	V := [][]Value{V0, append([]Value{}, V0...)}
 	prev, cur := 0, 1
 	for {
-		diff := false // Are this iteration's values different than the previous?
-		for i, state := range states {
-			if len(state.Action) == 0 {
-				continue
-			}
-			switch state.Player {
-			case Nature:
-				ev := Value(0.0) // Expected value of reward for next state.
-				for _, action := range state.Action {
-					s := action.NextState
-					ev += Value(action.Prob) * (states[s].Reward + γ*V[prev][s])
-				}
-				V[cur][i] = ev
-			case Player1:
-				max := negInf // Maximum value of reward for next state.
-				for _, action := range state.Action {
-					s := action.NextState
-					max = math.Max(max, float64(states[s].Reward+γ*V[prev][s]))
-				}
-				V[cur][i] = Value(max)
-			}
-			if math.Abs(float64(V[prev][i]-V[cur][i])) > tolerance {
-				diff = true
-			}
+		if err := sweep(V[prev], V[cur]); err != nil {
+			return V[prev], stats, err
+		}
+		stats.Residual = 0
+		for i, v := range V[cur] {
+			stats.Residual = math.Max(stats.Residual, math.Abs(float64(V[prev][i]-v)))
+		}
+		stats.Iterations++
+		stats.Residuals = append(stats.Residuals, stats.Residual)
+		stats.Elapsed = time.Since(start)
+		if progress != nil {
+			progress(stats)
 		}
-		if !diff {
+		if !(stats.Residual > tolerance) { // Stop on NaN, too.
 			break
 		}
 		prev, cur = cur, prev
 	}
-	return V[0]
+	return V[cur], stats, nil
 }
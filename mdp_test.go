@@ -61,3 +61,34 @@ func TestValues(t *testing.T) {
 		}
 	}
 }
+
+func TestSolve(t *testing.T) {
+	// You flip a coin until it comes up heads. You get a dollar for every flip.
+	states := MDP{
+		State{Nature, 1, []Action{{0, 0.5}, {1, 0.5}}},
+		State{Nature, 1, []Action{}},
+	}
+	calls := 0
+	V, stats := states.Solve(1.0, 1e-6, func(s Stats) {
+		calls++
+		if s.Iterations != calls || len(s.Residuals) != calls {
+			t.Errorf("progress call %d: got Stats %+v", calls, s)
+		}
+	})
+	// V[0] after n iterations is 2-2^(1-n), so the residual halves each time.
+	if stats.Iterations != 21 || calls != 21 {
+		t.Errorf("Solve() took %d iterations with %d progress calls; want 21", stats.Iterations, calls)
+	}
+	if stats.Residual != stats.Residuals[len(stats.Residuals)-1] || stats.Residual > 1e-6 {
+		t.Errorf("Solve() residual %v, residuals %v", stats.Residual, stats.Residuals)
+	}
+	for i := 1; i < len(stats.Residuals); i++ {
+		if stats.Residuals[i] != stats.Residuals[i-1]/2 {
+			t.Errorf("Solve() residuals %v; want each half the last", stats.Residuals)
+			break
+		}
+	}
+	if math.Abs(float64(V[0]-2)) > 1e-6 {
+		t.Errorf("Solve() V[0]=%v; want 2", V[0])
+	}
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"fmt"
+
+	mdp "github.com/jordancurve/games"
+)
+
+// columnHeight is the number of spaces in each column of a Can't Stop board,
+// indexed by the column's sum.
+var columnHeight = [13]int{2: 3, 3: 5, 4: 7, 5: 9, 6: 11, 7: 13, 8: 11, 9: 9, 10: 7, 11: 5, 12: 3}
+
+// CantStopColumn returns a model of climbing a single column of Can't Stop
+// with one marker.  Each roll of four dice advances the marker once for each
+// of two pairs summing to column (the player picks the best pairing); if no
+// pair does, the turn ends and the marker's progress is lost.  After any roll
+// the player may stop and keep the progress.  Each turn is worth -1, so the
+// value of the game is minus the expected number of turns to reach the top.
+// Columns are numbered from 2 to 12; CantStopColumn panics for any other.
+func CantStopColumn(column int) Model {
+	if column < 2 || column > 12 {
+		panic(fmt.Sprintf("models: CantStopColumn(%d): column must be from 2 to 12", column))
+	}
+	height := columnHeight[column]
+	one, two := columnOdds(column)
+	b := newBuilder()
+	top := b.state("top", mdp.Nature, 0)
+	turn := func(banked int) string { return fmt.Sprintf("banked %d", banked) }
+	b.state("start", mdp.Nature, 0)
+	b.add("start", b.id(turn(0)), 1)
+	for banked := 0; banked < height; banked++ {
+		b.state(turn(banked), mdp.Nature, -1)
+		b.add(turn(banked), b.id(fmt.Sprintf("banked %d marker 0", banked)), 1)
+		for marker := 0; banked+marker < height; marker++ {
+			name := fmt.Sprintf("banked %d marker %d", banked, marker)
+			roll := fmt.Sprintf("banked %d marker %d roll", banked, marker)
+			b.state(name, mdp.Player1, 0)
+			b.add(name, b.id(roll), 0)
+			if marker > 0 {
+				b.add(name, b.id(turn(banked+marker)), 0)
+			}
+			b.state(roll, mdp.Nature, 0)
+			b.add(roll, b.id(turn(banked)), 1-one-two)
+			for step, p := range []float64{1: one, 2: two} {
+				if step == 0 {
+					continue
+				}
+				next := top
+				if banked+marker+step < height {
+					next = b.id(fmt.Sprintf("banked %d marker %d", banked, marker+step))
+				}
+				b.add(roll, next, p)
+			}
+		}
+	}
+	return b.model("start")
+}
+
+// columnOdds returns the probabilities that a roll of four dice can advance a
+// marker in column exactly once, and twice.
+func columnOdds(column int) (one, two float64) {
+	var d [4]int
+	for i := 0; i < 6*6*6*6; i++ {
+		for j, n := 0, i; j < 4; j, n = j+1, n/6 {
+			d[j] = n%6 + 1
+		}
+		best := 0
+		for _, p := range [][4]int{{0, 1, 2, 3}, {0, 2, 1, 3}, {0, 3, 1, 2}} {
+			n := 0
+			if d[p[0]]+d[p[1]] == column {
+				n++
+			}
+			if d[p[2]]+d[p[3]] == column {
+				n++
+			}
+			if n > best {
+				best = n
+			}
+		}
+		switch best {
+		case 1:
+			one++
+		case 2:
+			two++
+		}
+	}
+	return one / 1296, two / 1296
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"fmt"
+
+	mdp "github.com/jordancurve/games"
+)
+
+// Craps returns a model of a single one-unit bet at craps.  The player
+// chooses the pass line, the don't pass line (12 on the come-out roll is a
+// push) or the field (2 and 12 pay double).  The value of the game is the
+// player's expected winnings.
+func Craps() Model {
+	b := newBuilder()
+	win := b.state("win", mdp.Nature, 1)
+	win2 := b.state("win double", mdp.Nature, 2)
+	lose := b.state("lose", mdp.Nature, -1)
+	push := b.state("push", mdp.Nature, 0)
+	roll := func(sum int) float64 {
+		return float64(6-abs(sum-7)) / 36
+	}
+	b.state("come out", mdp.Player1, 0)
+	for _, bet := range []string{"pass", "don't pass", "field"} {
+		b.state(bet, mdp.Nature, 0)
+		b.add("come out", b.id(bet), 0)
+	}
+	for sum := 2; sum <= 12; sum++ {
+		switch sum {
+		case 7, 11:
+			b.add("pass", win, roll(sum))
+			b.add("don't pass", lose, roll(sum))
+		case 2, 3:
+			b.add("pass", lose, roll(sum))
+			b.add("don't pass", win, roll(sum))
+		case 12:
+			b.add("pass", lose, roll(sum))
+			b.add("don't pass", push, roll(sum))
+		default:
+			point := fmt.Sprintf("pass point %d", sum)
+			b.state(point, mdp.Nature, 0)
+			b.add("pass", b.id(point), roll(sum))
+			dpoint := fmt.Sprintf("don't pass point %d", sum)
+			b.state(dpoint, mdp.Nature, 0)
+			b.add("don't pass", b.id(dpoint), roll(sum))
+			for again := 2; again <= 12; again++ {
+				switch again {
+				case sum:
+					b.add(point, win, roll(again))
+					b.add(dpoint, lose, roll(again))
+				case 7:
+					b.add(point, lose, roll(again))
+					b.add(dpoint, win, roll(again))
+				default:
+					b.add(point, b.id(point), roll(again))
+					b.add(dpoint, b.id(dpoint), roll(again))
+				}
+			}
+		}
+		switch sum {
+		case 2, 12:
+			b.add("field", win2, roll(sum))
+		case 3, 4, 9, 10, 11:
+			b.add("field", win, roll(sum))
+		default:
+			b.add("field", lose, roll(sum))
+		}
+	}
+	return b.model("come out")
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,78 @@
+// Package models generates MDPs for classic dice and betting games, so that
+// they don't have to be numbered by hand.  Every state of a generated model
+// has a name, and the game starts in state Start.
+package models
+
+import (
+	mdp "github.com/jordancurve/games"
+)
+
+// Model is an MDP whose states are named.
+type Model struct {
+	mdp.MDP
+	Names []string
+	Start uint
+}
+
+// State returns the index of the state with the given name.
+func (m Model) State(name string) (uint, bool) {
+	for i, n := range m.Names {
+		if n == name {
+			return uint(i), true
+		}
+	}
+	return 0, false
+}
+
+// Value returns the value of the start state under the optimal policy.
+func (m Model) Value(discount, tolerance float64) mdp.Value {
+	return m.Values(discount, tolerance)[m.Start]
+}
+
+// builder numbers the states of a model as they are named.
+type builder struct {
+	m     Model
+	index map[string]uint
+}
+
+func newBuilder() *builder {
+	return &builder{index: map[string]uint{}}
+}
+
+// id returns the index of the named state, adding it if necessary.
+func (b *builder) id(name string) uint {
+	if i, ok := b.index[name]; ok {
+		return i
+	}
+	i := uint(len(b.m.MDP))
+	b.index[name] = i
+	b.m.MDP = append(b.m.MDP, mdp.State{Action: []mdp.Action{}})
+	b.m.Names = append(b.m.Names, name)
+	return i
+}
+
+// state sets the player and reward of the named state and returns its index.
+func (b *builder) state(name string, player mdp.Player, reward mdp.Value) uint {
+	i := b.id(name)
+	b.m.MDP[i].Player = player
+	b.m.MDP[i].Reward = reward
+	return i
+}
+
+// add adds an action from the named state to state next.  The probabilities
+// of actions to the same state are summed.
+func (b *builder) add(name string, next uint, prob float64) {
+	i := b.id(name)
+	for j, a := range b.m.MDP[i].Action {
+		if a.NextState == next {
+			b.m.MDP[i].Action[j].Prob += prob
+			return
+		}
+	}
+	b.m.MDP[i].Action = append(b.m.MDP[i].Action, mdp.Action{NextState: next, Prob: prob})
+}
+
+func (b *builder) model(start string) Model {
+	b.m.Start = b.id(start)
+	return b.m
+}
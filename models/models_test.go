@@ -0,0 +1,120 @@
+package models
+
+import (
+	"math"
+	"testing"
+
+	mdp "github.com/jordancurve/games"
+)
+
+func TestModels(t *testing.T) {
+	cases := []struct {
+		name      string
+		model     Model
+		state     string // Defaults to the start state.
+		want      mdp.Value
+		tolerance float64
+	}{
+		// A turn of Pig solitaire ends with probability 1/6 before reaching 2.
+		{"Pig(2)", Pig(2), "", -1.2, 1e-9},
+		{"Pig(100)", Pig(100), "", -12.5452, 1e-4},
+		// Bus Ticket Roulette, from The Population Explosion by Dick Hess.
+		{"Roulette(1, 4, 1, 18/38)", Roulette(1, 4, 1, 18.0/38), "", (18.0 * 18.0) / (38 * 38), 1e-9},
+		{"Roulette(2, 4, 1, 18/38)", Roulette(2, 4, 1, 18.0/38), "", 18.0 / 38, 1e-9},
+		{"Roulette(3, 4, 1, 18/38)", Roulette(3, 4, 1, 18.0/38), "", 18.0/38 + (18.0*20.0)/(38*38), 1e-9},
+		{"Roulette(10, 20, 1, 18/38)", Roulette(10, 20, 1, 18.0/38), "", 18.0 / 38, 1e-9},
+		{"Roulette(1, 36, 35, 1/38)", Roulette(1, 36, 35, 1.0/38), "", 1.0 / 38, 1e-9},
+		{"Roulette(4, 4, 1, 18/38)", Roulette(4, 4, 1, 18.0/38), "", 1, 1e-9},
+		{"Roulette(5, 4, 1, 18/38)", Roulette(5, 4, 1, 18.0/38), "", 1, 1e-9},
+		// House edges of the standard bets.
+		{"Craps()", Craps(), "pass", -7.0 / 495, 1e-9},
+		{"Craps()", Craps(), "don't pass", -3.0 / 220, 1e-9},
+		{"Craps()", Craps(), "field", -1.0 / 18, 1e-9},
+		{"Craps()", Craps(), "", -3.0 / 220, 1e-9},
+		{"YahtzeeTurn(Yahtzee)", YahtzeeTurn(Yahtzee), "", 50 * 2783176.0 / 60466176, 1e-9},
+		{"YahtzeeTurn(Sixes)", YahtzeeTurn(Sixes), "", 6 * 5 * (1 - 125.0/216), 1e-9},
+		{"YahtzeeTurn(Chance)", YahtzeeTurn(Chance), "", 70.0 / 3, 1e-9},
+		// In column 2, a roll advances the marker with probability only
+		// 171/1296, with two 1s, and twice with 1/1296, with four, so it is
+		// best to stop after every advance.  Then the expected number of
+		// turns to climb h spaces is E(h) = (1 + 170/1296 E(h-1) +
+		// 1/1296 E(h-2)) / (171/1296), and E(3) = 1398112/61731.
+		{"CantStopColumn(2)", CantStopColumn(2), "", -1398112.0 / 61731, 1e-9},
+	}
+	for _, c := range cases {
+		i := c.model.Start
+		if c.state != "" {
+			var ok bool
+			if i, ok = c.model.State(c.state); !ok {
+				t.Errorf("%s: no state %q", c.name, c.state)
+				continue
+			}
+		}
+		got := c.model.Values(1.0, 1e-12)[i]
+		if math.Abs(float64(got-c.want)) > c.tolerance {
+			t.Errorf("%s V[%q]: got %v; want %v", c.name, c.model.Names[i], got, c.want)
+		}
+	}
+}
+
+func TestCantStopColumn(t *testing.T) {
+	for column := 2; column <= 12; column++ {
+		// Stopping after every advance takes E(h) turns, as for column 2
+		// in TestModels, and the best policy is no worse.
+		one, two := columnOdds(column)
+		E := []float64{0, 1 / (one + two)}
+		for h := 2; h <= columnHeight[column]; h++ {
+			E = append(E, (1+one*E[h-1]+two*E[h-2])/(one+two))
+		}
+		m := CantStopColumn(column)
+		got := float64(m.Values(1.0, 1e-12)[m.Start])
+		if got < -E[len(E)-1]-1e-9 {
+			t.Errorf("CantStopColumn(%d)=%v; want at least %v, the value of stopping after every advance", column, got, -E[len(E)-1])
+		}
+	}
+	for _, column := range []int{1, 13} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("CantStopColumn(%d) didn't panic", column)
+				}
+			}()
+			CantStopColumn(column)
+		}()
+	}
+}
+
+func TestColumnOdds(t *testing.T) {
+	// Chance that a roll of four dice can make each sum.
+	want := map[int]float64{2: 0.132, 3: 0.233, 4: 0.356, 5: 0.448, 6: 0.561, 7: 0.644, 8: 0.561, 12: 0.132}
+	for column, w := range want {
+		one, two := columnOdds(column)
+		if got := one + two; math.Abs(got-w) > 5e-4 {
+			t.Errorf("columnOdds(%d)=%v+%v=%v; want %v", column, one, two, got, w)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	cases := []struct {
+		c    Category
+		dice Dice
+		want int
+	}{
+		{Threes, Dice{0, 0, 3, 0, 2, 0}, 9},
+		{ThreeOfAKind, Dice{0, 0, 3, 0, 2, 0}, 19},
+		{FourOfAKind, Dice{0, 0, 3, 0, 2, 0}, 0},
+		{FullHouse, Dice{0, 0, 3, 0, 2, 0}, 25},
+		{FullHouse, Dice{0, 0, 5, 0, 0, 0}, 0},
+		{SmallStraight, Dice{1, 1, 1, 1, 0, 1}, 30},
+		{LargeStraight, Dice{1, 1, 1, 1, 0, 1}, 0},
+		{LargeStraight, Dice{0, 1, 1, 1, 1, 1}, 40},
+		{Yahtzee, Dice{0, 0, 0, 0, 0, 5}, 50},
+		{Chance, Dice{1, 1, 1, 1, 0, 1}, 16},
+	}
+	for _, c := range cases {
+		if got := c.c.Score(c.dice); got != c.want {
+			t.Errorf("Category(%d).Score(%v)=%d; want %d", c.c, c.dice, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"fmt"
+
+	mdp "github.com/jordancurve/games"
+)
+
+// Pig returns a model of Pig solitaire: the player rolls a die as often as
+// they like, adding each roll to their turn total, and may hold to bank the
+// turn total.  Rolling a 1 ends the turn and loses the turn total.  The goal is
+// to bank target points in as few turns as possible, so each turn is worth -1
+// and the value of the game is minus the expected number of turns.
+func Pig(target int) Model {
+	b := newBuilder()
+	win := b.state("win", mdp.Nature, 0)
+	turn := func(score int) string { return fmt.Sprintf("score %d", score) }
+	b.state("start", mdp.Nature, 0)
+	b.add("start", b.id(turn(0)), 1)
+	for score := 0; score < target; score++ {
+		b.state(turn(score), mdp.Nature, -1)
+		b.add(turn(score), b.id(fmt.Sprintf("score %d turn 0", score)), 1)
+		for total := 0; score+total < target; total++ {
+			name := fmt.Sprintf("score %d turn %d", score, total)
+			roll := fmt.Sprintf("score %d turn %d roll", score, total)
+			b.state(name, mdp.Player1, 0)
+			b.add(name, b.id(roll), 0)
+			if total > 0 {
+				b.add(name, b.id(turn(score+total)), 0)
+			}
+			b.state(roll, mdp.Nature, 0)
+			b.add(roll, b.id(turn(score)), 1.0/6)
+			for die := 2; die <= 6; die++ {
+				next := win
+				if score+total+die < target {
+					next = b.id(fmt.Sprintf("score %d turn %d", score, total+die))
+				}
+				b.add(roll, next, 1.0/6)
+			}
+		}
+	}
+	return b.model("start")
+}
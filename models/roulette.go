@@ -0,0 +1,42 @@
+package models
+
+import (
+	"fmt"
+
+	mdp "github.com/jordancurve/games"
+)
+
+// Roulette returns a model of a gambler with the given bankroll who wants to
+// reach target by making whole-dollar bets that win with probability p and
+// pay payout to 1.  The value of the game is the probability of reaching the
+// target.  For subfair even-money bets the optimal policy is bold play:
+// always bet everything, or just enough to reach the target.  A gambler who
+// starts with the target has already won, with value 1.
+func Roulette(bankroll, target, payout int, p float64) Model {
+	b := newBuilder()
+	win := b.state("win", mdp.Nature, 1)
+	b.state("$0", mdp.Nature, 0)
+	for have := 1; have < target; have++ {
+		name := fmt.Sprintf("$%d", have)
+		b.state(name, mdp.Player1, 0)
+		for bet := 1; bet <= have; bet++ {
+			next := win
+			if have+bet*payout < target {
+				next = b.id(fmt.Sprintf("$%d", have+bet*payout))
+			}
+			spin := fmt.Sprintf("$%d bet $%d", have, bet)
+			b.state(spin, mdp.Nature, 0)
+			b.add(spin, next, p)
+			b.add(spin, b.id(fmt.Sprintf("$%d", have-bet)), 1-p)
+			b.add(name, b.id(spin), 0)
+		}
+	}
+	if bankroll >= target {
+		// Rewards are earned on entering a state, so start just before
+		// the win.
+		b.state("start", mdp.Nature, 0)
+		b.add("start", win, 1)
+		return b.model("start")
+	}
+	return b.model(fmt.Sprintf("$%d", bankroll))
+}
@@ -0,0 +1,201 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	mdp "github.com/jordancurve/games"
+)
+
+// Category is a Yahtzee scoring category.
+type Category int
+
+const (
+	Ones Category = iota + 1
+	Twos
+	Threes
+	Fours
+	Fives
+	Sixes
+	ThreeOfAKind
+	FourOfAKind
+	FullHouse
+	SmallStraight
+	LargeStraight
+	Yahtzee
+	Chance
+)
+
+// Dice counts how many of each face, from 1 to 6, were rolled.
+type Dice [6]int
+
+func (d Dice) String() string {
+	faces := []string{}
+	for f, n := range d {
+		for i := 0; i < n; i++ {
+			faces = append(faces, fmt.Sprint(f+1))
+		}
+	}
+	return strings.Join(faces, " ")
+}
+
+func (d Dice) sum() int {
+	s := 0
+	for f, n := range d {
+		s += (f + 1) * n
+	}
+	return s
+}
+
+func (d Dice) size() int {
+	s := 0
+	for _, n := range d {
+		s += n
+	}
+	return s
+}
+
+// run returns the length of the longest straight in d.
+func (d Dice) run() int {
+	longest, cur := 0, 0
+	for _, n := range d {
+		if n == 0 {
+			cur = 0
+			continue
+		}
+		cur++
+		if cur > longest {
+			longest = cur
+		}
+	}
+	return longest
+}
+
+// Score returns the score of five dice in category c.
+func (c Category) Score(d Dice) int {
+	most, pair := 0, false
+	for _, n := range d {
+		if n > most {
+			most = n
+		}
+		if n == 2 {
+			pair = true
+		}
+	}
+	switch {
+	case c >= Ones && c <= Sixes:
+		return int(c) * d[c-1]
+	case c == ThreeOfAKind && most >= 3, c == FourOfAKind && most >= 4:
+		return d.sum()
+	case c == FullHouse && most == 3 && pair:
+		return 25
+	case c == SmallStraight && d.run() >= 4:
+		return 30
+	case c == LargeStraight && d.run() == 5:
+		return 40
+	case c == Yahtzee && most == 5:
+		return 50
+	case c == Chance:
+		return d.sum()
+	}
+	return 0
+}
+
+// YahtzeeTurn returns a model of a single Yahtzee turn scored in category c:
+// roll five dice, then twice keep any of them and reroll the rest.  The value
+// of the game is the expected score.
+func YahtzeeTurn(c Category) Model {
+	b := newBuilder()
+	rolled := func(roll int, d Dice) string { return fmt.Sprintf("roll %d: %v", roll, d) }
+	kept := func(roll int, d Dice) string { return fmt.Sprintf("roll %d keep: %v", roll, d) }
+	rollFrom := func(name string, roll int, keep Dice) {
+		b.state(name, mdp.Nature, 0)
+		for _, o := range outcomes(5 - keep.size()) {
+			d := keep
+			for f := range d {
+				d[f] += o.dice[f]
+			}
+			b.add(name, b.id(rolled(roll, d)), o.prob)
+		}
+	}
+	rollFrom("start", 1, Dice{})
+	for roll := 1; roll <= 3; roll++ {
+		for _, o := range outcomes(5) {
+			name := rolled(roll, o.dice)
+			b.state(name, mdp.Player1, 0)
+			score := fmt.Sprintf("score: %v", o.dice)
+			b.state(score, mdp.Nature, mdp.Value(c.Score(o.dice)))
+			b.add(name, b.id(score), 0)
+			if roll == 3 {
+				continue
+			}
+			for _, keep := range subsets(o.dice) {
+				if keep.size() == 5 {
+					continue
+				}
+				k := kept(roll, keep)
+				if _, ok := b.index[k]; !ok {
+					rollFrom(k, roll+1, keep)
+				}
+				b.add(name, b.id(k), 0)
+			}
+		}
+	}
+	return b.model("start")
+}
+
+type diceOutcome struct {
+	dice Dice
+	prob float64
+}
+
+// outcomes returns the distribution of rolling n dice.
+func outcomes(n int) []diceOutcome {
+	out := []diceOutcome{}
+	var gen func(face, left int, d Dice)
+	gen = func(face, left int, d Dice) {
+		if face == 5 {
+			d[face] = left
+			// Multinomial coefficient n!/(d[0]!...d[5]!) over 6^n.
+			p := float64(factorial(n))
+			for _, k := range d {
+				p /= float64(factorial(k))
+			}
+			for i := 0; i < n; i++ {
+				p /= 6
+			}
+			out = append(out, diceOutcome{d, p})
+			return
+		}
+		for k := 0; k <= left; k++ {
+			d[face] = k
+			gen(face+1, left-k, d)
+		}
+	}
+	gen(0, n, Dice{})
+	return out
+}
+
+// subsets returns every sub-multiset of d.
+func subsets(d Dice) []Dice {
+	out := []Dice{{}}
+	for f, n := range d {
+		more := []Dice{}
+		for _, s := range out {
+			for k := 1; k <= n; k++ {
+				s[f] = k
+				more = append(more, s)
+			}
+		}
+		out = append(out, more...)
+	}
+	return out
+}
+
+func factorial(n int) int {
+	f := 1
+	for i := 2; i <= n; i++ {
+		f *= i
+	}
+	return f
+}
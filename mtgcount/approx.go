@@ -0,0 +1,487 @@
+package mtgcount
+
+import (
+	"context"
+	"math"
+	"sort"
+)
+
+/*
+CountDecksLog10 approximates log10 of CountDecks in float64, which is much
+faster than the exact count when decks are large, and never overflows.
+
+The count is the coefficient of x^M y^S in F(x, y) = Π_g P_g(x, y)^n_g, where
+group g has n_g cards with limit L_g and P_g = Σ x^a y^b over a+b ≤ L_g.  For
+any x0, y0 > 0 and constants N_g, the count is also
+
+	[x^M y^S] Π_g (P_g(x0 x, y0 y)/N_g)^n_g · Π_g N_g^n_g / (x0^M y0^S).
+
+Choosing N_g = P_g(x0, y0), the polynomials in the product are probability
+distributions, whose coefficients are at most 1, and choosing (x0, y0) at
+the saddle point of F(x, y)/(x^M y^S) centers the distribution of the total
+on (M, S), so that the coefficient is not too small.
+
+Every coefficient is nonnegative, so rounding errors don't cancel: each
+computed coefficient c of an exact c* satisfies |c - c*| ≤ rel·c* + abs,
+where rel bounds the accumulated relative rounding error and abs the
+absolute error from underflow, and both are tracked through each operation.
+The bounds assume that math.Exp and math.Log are accurate to within an ulp.
+*/
+
+const (
+	unit = 0x1p-53   // Unit roundoff of float64.
+	tiny = 0x1p-1074 // Absolute error bound of a float64 operation that underflows.
+)
+
+// fpoly is a polynomial with float64 coefficients, each within rel·c*+abs
+// of the exact c*.
+type fpoly struct {
+	c        [][]float64
+	rel, abs float64
+}
+
+func newFpoly(main, side int) fpoly {
+	c := make([][]float64, main+1)
+	for m := range c {
+		c[m] = make([]float64, side+1)
+	}
+	return fpoly{c: c}
+}
+
+// grow returns the relative error bound after k more roundings of a value
+// with relative error bound rel: (1+rel)(1+unit)^k - 1, rounded up.
+func grow(rel float64, k int) float64 {
+	return ((1+rel)*math.Exp(float64(k)*unit*1.0001) - 1) * (1 + 1e-12)
+}
+
+// sum returns an upper bound on the sum of the exact coefficients of p.
+func (p fpoly) sum() float64 {
+	s, n := 0.0, 0
+	for _, row := range p.c {
+		for _, c := range row {
+			s += c
+			n++
+		}
+	}
+	return grow(p.rel, n)*s + s + float64(n)*p.abs
+}
+
+// mul returns p*q, truncated, with its error bounds, or ctx's error if ctx
+// is done first.
+func (p fpoly) mul(ctx context.Context, q fpoly) (fpoly, error) {
+	main, side := len(p.c)-1, len(p.c[0])-1
+	type term struct {
+		m, s int
+		c    float64
+	}
+	terms := []term{}
+	for m, row := range q.c {
+		for s, c := range row {
+			if c != 0 {
+				terms = append(terms, term{m, s, c})
+			}
+		}
+	}
+	r := newFpoly(main, side)
+	for m, row := range p.c {
+		if err := ctx.Err(); err != nil {
+			return fpoly{}, err
+		}
+		for s, a := range row {
+			if a == 0 {
+				continue
+			}
+			for _, b := range terms {
+				if m+b.m <= main && s+b.s <= side {
+					r.c[m+b.m][s+b.s] += a * b.c
+				}
+			}
+		}
+	}
+	// Each coefficient is a sum of at most len(terms) products.
+	t := len(terms) + 1
+	r.rel = grow(p.rel+q.rel+p.rel*q.rel, 2*t)
+	r.abs = p.abs*q.sum() + q.abs*p.sum() + float64(t)*(p.abs*q.abs+2*tiny)
+	return r, nil
+}
+
+// boxTerms returns the exponents (a, b) of x^a y^b with a ≤ main, b ≤ side
+// and a+b ≤ lim.
+func boxTerms(lim, main, side int) [][2]int {
+	terms := [][2]int{}
+	for a := 0; a <= main; a++ {
+		for b := 0; b <= side && a+b <= lim; b++ {
+			terms = append(terms, [2]int{a, b})
+		}
+	}
+	return terms
+}
+
+// logSumExp returns log Σ exp(t) without overflowing.
+func logSumExp(t []float64) float64 {
+	max := math.Inf(-1)
+	for _, x := range t {
+		max = math.Max(max, x)
+	}
+	sum := 0.0
+	for _, x := range t {
+		sum += math.Exp(x - max)
+	}
+	return max + math.Log(sum)
+}
+
+type approxGroup struct {
+	n     int
+	terms [][2]int
+}
+
+// saddle returns (u, v) = (log x0, log y0) near the minimum of
+// Σ n_g log P_g(e^u, e^v) - M u - S v, by damped Newton's method.  Any
+// result is correct; a better one only loses less to underflow.
+func saddle(groups []approxGroup, main, side int) (u, v float64) {
+	f := func(u, v float64) (val float64, grad [2]float64, hess [2][2]float64) {
+		val = -float64(main)*u - float64(side)*v
+		grad = [2]float64{-float64(main), -float64(side)}
+		t := []float64{}
+		for _, g := range groups {
+			t = t[:0]
+			for _, ab := range g.terms {
+				t = append(t, float64(ab[0])*u+float64(ab[1])*v)
+			}
+			logN := logSumExp(t)
+			val += float64(g.n) * logN
+			var ea, eb, eaa, eab, ebb float64
+			for i, ab := range g.terms {
+				w := math.Exp(t[i] - logN)
+				a, b := float64(ab[0]), float64(ab[1])
+				ea, eb = ea+w*a, eb+w*b
+				eaa, eab, ebb = eaa+w*a*a, eab+w*a*b, ebb+w*b*b
+			}
+			n := float64(g.n)
+			grad[0] += n * ea
+			grad[1] += n * eb
+			hess[0][0] += n * (eaa - ea*ea)
+			hess[0][1] += n * (eab - ea*eb)
+			hess[1][1] += n * (ebb - eb*eb)
+		}
+		hess[1][0] = hess[0][1]
+		return val, grad, hess
+	}
+	clamp := func(x float64) float64 { return math.Max(-50, math.Min(50, x)) }
+	for iter := 0; iter < 50; iter++ {
+		val, g, h := f(u, v)
+		// Only move in the directions with a positive target.
+		var du, dv float64
+		switch {
+		case main > 0 && side > 0:
+			det := h[0][0]*h[1][1] - h[0][1]*h[1][0]
+			if det <= 0 {
+				return u, v
+			}
+			du = -(h[1][1]*g[0] - h[0][1]*g[1]) / det
+			dv = -(h[0][0]*g[1] - h[1][0]*g[0]) / det
+		case main > 0 && h[0][0] > 0:
+			du = -g[0] / h[0][0]
+		case side > 0 && h[1][1] > 0:
+			dv = -g[1] / h[1][1]
+		default:
+			return u, v
+		}
+		step := 1.0
+		for ; step > 1e-6; step /= 2 {
+			if next, _, _ := f(clamp(u+step*du), clamp(v+step*dv)); next < val {
+				break
+			}
+		}
+		if step <= 1e-6 {
+			return u, v
+		}
+		u, v = clamp(u+step*du), clamp(v+step*dv)
+		if math.Abs(du)+math.Abs(dv) < 1e-9 {
+			break
+		}
+	}
+	return u, v
+}
+
+// groupFpoly returns the tilted, normalized generating function of the n
+// cards of g, (Σ p_ab x^a y^b)^n where p_ab = exp(a u + b v - logN), along
+// with logN, or ctx's error if ctx is done first.
+func groupFpoly(ctx context.Context, g approxGroup, u, v float64, main, side int) (fpoly, float64, error) {
+	if len(g.terms) == (main+1)*(side+1) {
+		p, logN := separableFpoly(g.n, u, v, main, side)
+		return p, logN, nil
+	}
+	t := []float64{}
+	bound := 0.0 // Bound on |a u| + |b v|.
+	for _, ab := range g.terms {
+		au, bv := float64(ab[0])*u, float64(ab[1])*v
+		t = append(t, au+bv)
+		bound = math.Max(bound, math.Abs(au)+math.Abs(bv))
+	}
+	logN := logSumExp(t)
+	// p = p0 + q, where q has no constant term.  Each coefficient is
+	// exp(a u + b v - logN) with its argument off by at most argErr.
+	argErr := 4 * unit * (bound + math.Abs(logN))
+	q := newFpoly(main, side)
+	p0, sumQ := 0.0, 0.0
+	for i, ab := range g.terms {
+		c := math.Exp(t[i] - logN)
+		if ab == [2]int{0, 0} {
+			p0 = c
+			continue
+		}
+		q.c[ab[0]][ab[1]] = c
+		sumQ += c
+	}
+	cardRel := grow(math.Expm1(argErr), 1)
+	// p^n = Σ_k C(n, k) p0^(n-k) sumQ^k r^k, where r = q/sumQ.
+	r := newFpoly(main, side)
+	for m, row := range q.c {
+		for s, c := range row {
+			r.c[m][s] = c / sumQ
+		}
+	}
+	// sumQ is just a constant, and r = q/sumQ, so only the errors of q and
+	// the division count.
+	r.rel = grow(cardRel, 1)
+	r.abs = tiny * 2
+	// log of C(n, k) p0^(n-k) sumQ^k, and a bound on its error.
+	logP0, logQ := math.Log(p0), math.Log(sumQ)
+	logC, logCMag := 0.0, 0.0
+	gp := newFpoly(main, side)
+	gp.c[0][0] = math.Exp(float64(g.n) * logP0)
+	maxRel, abs := grow(cardRel*float64(g.n)*1.0001+4*unit*float64(g.n)*math.Abs(logP0), 2), 2*tiny
+	rk := newFpoly(main, side)
+	rk.c[0][0] = 1
+	for k := 1; k <= g.n && k <= main+side; k++ {
+		var err error
+		if rk, err = rk.mul(ctx, r); err != nil {
+			return fpoly{}, 0, err
+		}
+		lt := math.Log(float64(g.n-k+1)) - math.Log(float64(k))
+		logC += lt
+		logCMag += math.Abs(lt) + 1
+		logW := logC + float64(g.n-k)*logP0 + float64(k)*logQ
+		wErr := 4 * unit * (logCMag + float64(g.n-k)*math.Abs(logP0) + float64(k)*math.Abs(logQ) + math.Abs(logW))
+		// The weight's own error, plus that of the n-k factors of p0 and
+		// the k of sumQ, which are the exact constants chosen, and so only
+		// enter through p0's error as a coefficient.
+		w := math.Exp(logW)
+		wRel := grow(math.Expm1(wErr)+cardRel*float64(g.n-k)*1.0001, 2)
+		for m, row := range rk.c {
+			for s, c := range row {
+				gp.c[m][s] += w * c
+			}
+		}
+		maxRel = math.Max(maxRel, grow(wRel+rk.rel+wRel*rk.rel, 2))
+		abs += w*rk.abs + 2*tiny
+	}
+	k := g.n
+	if k > main+side {
+		k = main + side
+	}
+	gp.rel = grow(maxRel, k+1)
+	gp.abs = abs
+	return gp, logN, nil
+}
+
+// separableFpoly is groupFpoly for cards whose limit doesn't truncate the
+// box, where P = (Σ x^a)(Σ y^b), and so P^n has coefficients
+// C(n+a-1, a) C(n+b-1, b).
+func separableFpoly(n int, u, v float64, main, side int) (fpoly, float64) {
+	// series returns the logs of C(n+i-1, i) e^(i w) / N^n for i ≤ size,
+	// where N = Σ e^(i w), log N, and bounds on the errors of the former.
+	series := func(w float64, size int) (l, errs []float64, logN float64) {
+		t := []float64{}
+		for i := 0; i <= size; i++ {
+			t = append(t, float64(i)*w)
+		}
+		logN = logSumExp(t)
+		logC, mag := 0.0, 0.0
+		for i := 0; i <= size; i++ {
+			if i > 0 {
+				lt := math.Log(float64(n+i-1)) - math.Log(float64(i))
+				logC += lt
+				mag += math.Abs(lt) + 1
+			}
+			x := logC + t[i] - float64(n)*logN
+			l = append(l, x)
+			errs = append(errs, 4*unit*(mag+math.Abs(t[i])+float64(n)*math.Abs(logN)+math.Abs(x)))
+		}
+		return l, errs, logN
+	}
+	la, ea, logNa := series(u, main)
+	lb, eb, logNb := series(v, side)
+	p := newFpoly(main, side)
+	maxErr := 0.0
+	for a := range p.c {
+		for b := range p.c[a] {
+			p.c[a][b] = math.Exp(la[a] + lb[b])
+			maxErr = math.Max(maxErr, ea[a]+eb[b]+4*unit*math.Abs(la[a]+lb[b]))
+		}
+	}
+	p.rel = grow(math.Expm1(maxErr), 1)
+	p.abs = 2 * tiny
+	return p, logNa + logNb
+}
+
+// log10Groups returns the positive limits, capped at numMain+numSide, in
+// increasing order, the number of cards with each, and the sum of the limits.
+func log10Groups(numMain, numSide int, limit []int) (lims []int, sizes map[int]int, total int) {
+	sizes = map[int]int{}
+	for _, lim := range limit {
+		if lim > numMain+numSide {
+			lim = numMain + numSide
+		}
+		if lim > 0 {
+			sizes[lim]++
+			total += lim
+		}
+	}
+	for lim := range sizes {
+		lims = append(lims, lim)
+	}
+	sort.Ints(lims)
+	return lims, sizes, total
+}
+
+// CountDecksLog10Cost returns about how many multiplications
+// CountDecksLog10(numMain, numSide, limit) does, for callers bounding its
+// time before starting it.
+func CountDecksLog10Cost(numMain, numSide int, limit []int) float64 {
+	if numMain < 0 || numSide < 0 {
+		return 0
+	}
+	size := numMain + numSide
+	// terms returns the number of nonzero coefficients of a polynomial with
+	// every x^a y^b in the box with a+b ≤ deg.
+	terms := func(deg int) float64 {
+		if deg > size {
+			deg = size
+		}
+		n := 0
+		for a := 0; a <= numMain && a <= deg; a++ {
+			if deg-a < numSide {
+				n += deg - a + 1
+			} else {
+				n += numSide + 1
+			}
+		}
+		return float64(n)
+	}
+	box := terms(size)
+	lims, sizes, _ := log10Groups(numMain, numSide, limit)
+	cost, deg := 0.0, 0
+	for i, lim := range lims {
+		n := sizes[lim]
+		if lim < size {
+			// groupFpoly multiplies by r, of degree lim, up to n times.
+			t, kMax := terms(lim), n
+			if kMax > size {
+				kMax = size
+			}
+			for k := 1; k <= kMax; k++ {
+				if (k-1)*lim >= size {
+					cost += float64(kMax-k+1) * box * t
+					break
+				}
+				cost += terms((k-1)*lim) * t
+			}
+		} else {
+			cost += box
+		}
+		gDeg := size
+		if n < size && n*lim < size {
+			gDeg = n * lim
+		}
+		switch {
+		case i == 0:
+		case i < len(lims)-1:
+			cost += terms(deg) * terms(gDeg)
+		default:
+			cost += terms(deg)
+		}
+		if deg += gDeg; deg > size {
+			deg = size
+		}
+	}
+	return cost
+}
+
+// CountDecksLog10 returns log10 of CountDecks(numMain, numSide, limit),
+// approximately, and a bound on its error: the log10 of the exact count is
+// within err of the result.  If there are no decks, it returns -Inf and 0.
+func CountDecksLog10(numMain, numSide int, limit []int) (log10, err float64) {
+	log10, err, _ = CountDecksLog10Context(context.Background(), numMain, numSide, limit)
+	return log10, err
+}
+
+// CountDecksLog10Context is like CountDecksLog10, returning the error bound
+// as maxErr, but stops early if ctx is done, returning ctx's error.
+func CountDecksLog10Context(ctx context.Context, numMain, numSide int, limit []int) (log10, maxErr float64, err error) {
+	if numMain < 0 || numSide < 0 {
+		return math.Inf(-1), 0, nil
+	}
+	lims, sizes, total := log10Groups(numMain, numSide, limit)
+	if total < numMain+numSide {
+		return math.Inf(-1), 0, nil
+	}
+	if numMain+numSide == 0 {
+		return 0, 0, nil
+	}
+	groups := []approxGroup{}
+	for _, lim := range lims {
+		groups = append(groups, approxGroup{sizes[lim], boxTerms(lim, numMain, numSide)})
+	}
+	u, v := saddle(groups, numMain, numSide)
+	// L = Σ n_g logN_g - M u - S v, and a bound on its rounding error.
+	L := -float64(numMain)*u - float64(numSide)*v
+	mag := math.Abs(L)
+	prod := fpoly{}
+	var est float64
+	for i, g := range groups {
+		gp, logN, err := groupFpoly(ctx, g, u, v, numMain, numSide)
+		if err != nil {
+			return 0, 0, err
+		}
+		L += float64(g.n) * logN
+		mag += math.Abs(float64(g.n) * logN)
+		switch {
+		case len(groups) == 1:
+			prod = gp
+			est = gp.c[numMain][numSide]
+		case i == 0:
+			prod = gp
+		case i < len(groups)-1:
+			if prod, err = prod.mul(ctx, gp); err != nil {
+				return 0, 0, err
+			}
+		default:
+			// Only one coefficient of the last product is needed.
+			for m, row := range prod.c {
+				if err := ctx.Err(); err != nil {
+					return 0, 0, err
+				}
+				for s, c := range row {
+					est += c * gp.c[numMain-m][numSide-s]
+				}
+			}
+			n := (numMain + 1) * (numSide + 1)
+			prod.rel = grow(prod.rel+gp.rel+prod.rel*gp.rel, 2*n+2)
+			prod.abs = prod.abs*gp.sum() + gp.abs*prod.sum() + float64(n)*(prod.abs*gp.abs+2*tiny)
+		}
+	}
+	lErr := 4 * unit * (mag + float64(len(groups)+2)*math.Abs(L))
+	// est is within rel·c* + abs of the exact coefficient c*.
+	lo := (est - prod.abs) / (1 + prod.rel)
+	hi := (est + prod.abs) / (1 - prod.rel)
+	log10 = (math.Log(est) + L) / math.Ln10
+	if lo <= 0 {
+		return log10, math.Inf(1), nil
+	}
+	maxErr = math.Max(math.Log(hi)-math.Log(est), math.Log(est)-math.Log(lo)) + lErr
+	// Converting to base 10 rounds, too.
+	maxErr = maxErr/math.Ln10 + 4*unit*math.Abs(log10)
+	return log10, maxErr * (1 + 1e-9), nil
+}
@@ -0,0 +1,93 @@
+package mtgcount
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// bigLog10 returns log10 x, to float64 precision.
+func bigLog10(x *big.Int) float64 {
+	if x.Sign() == 0 {
+		return math.Inf(-1)
+	}
+	mant := new(big.Float)
+	exp := new(big.Float).SetInt(x).MantExp(mant)
+	f, _ := mant.Float64()
+	return math.Log10(f) + float64(exp)*math.Log10(2)
+}
+
+func checkLog10(t *testing.T, main, side int, limit []int, maxErr float64) {
+	t.Helper()
+	got, err := CountDecksLog10(main, side, limit)
+	want := bigLog10(CountDecks(main, side, limit))
+	if math.IsInf(want, -1) {
+		if !math.IsInf(got, -1) {
+			t.Errorf("CountDecksLog10(%d, %d, %v)=%v; want -Inf", main, side, limit, got)
+		}
+		return
+	}
+	if math.Abs(got-want) > err || err > maxErr {
+		t.Errorf("CountDecksLog10(%d, %d, %v)=%v±%v; want %v±%v", main, side, limit, got, err, want, maxErr)
+	}
+}
+
+func TestCountDecksLog10(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	choices := []int{0, 1, 2, 3, 4, 7, Unlimited}
+	for i := 0; i < 200; i++ {
+		limit := make([]int, r.Intn(12))
+		for j := range limit {
+			limit[j] = choices[r.Intn(len(choices))]
+		}
+		checkLog10(t, r.Intn(10), r.Intn(5), limit, 1e-12)
+	}
+	limit := benchLimits()
+	checkLog10(t, 60, 15, limit, 1e-9)
+	checkLog10(t, 100, 0, limit, 1e-9)
+	checkLog10(t, 0, 15, limit[:100], 1e-9)
+}
+
+func TestCountDecksLog10Cost(t *testing.T) {
+	cases := []struct {
+		main, side int
+		limit      []int
+		want       float64
+	}{
+		{-1, 0, []int{1}, 0},
+		{0, 0, nil, 0},
+		// Two multiplications by the 2 terms 1 and x, of 1 and then 1+x.
+		{2, 0, []int{1, 1, 1}, 6},
+		// The same for the limited cards, the unlimited card's 3 terms,
+		// and the coefficient of x^2 of their product.
+		{2, 0, []int{1, 1, Unlimited}, 6 + 3 + 3},
+	}
+	for _, c := range cases {
+		if got := CountDecksLog10Cost(c.main, c.side, c.limit); got != c.want {
+			t.Errorf("CountDecksLog10Cost(%d, %d, %v)=%v; want %v", c.main, c.side, c.limit, got, c.want)
+		}
+	}
+}
+
+func BenchmarkCountDecksLog10(b *testing.B) {
+	limit := benchLimits()
+	for i := 0; i < b.N; i++ {
+		CountDecksLog10(250, 100, limit)
+	}
+}
+
+func TestCountDecksLog10Context(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	limit := benchLimits()
+	if _, _, err := CountDecksLog10Context(ctx, 60, 15, limit); err != context.Canceled {
+		t.Errorf("CountDecksLog10Context(cancelled)=%v; want %v", err, context.Canceled)
+	}
+	got, gotErr, err := CountDecksLog10Context(context.Background(), 60, 15, limit)
+	want, wantErr := CountDecksLog10(60, 15, limit)
+	if err != nil || got != want || gotErr != wantErr {
+		t.Errorf("CountDecksLog10Context=%v±%v, %v; want %v±%v, nil", got, gotErr, err, want, wantErr)
+	}
+}
@@ -0,0 +1,234 @@
+// Calculate the number of legal decks (60 main + 15 sideboard) in various Magic the Gathering formats.
+//
+// Using card info from mtgjson version 4.6.3+20200508 (21045 cards):
+//
+//	standard: 3.96e+152 (395697481306288315500482412588185550997575949463159607457342791398956402454575201937306830423839076258993204642893660863880836081092733403218174252555980)
+//	  modern: 5.3e+216 (5303212499185418908525344852772915685049773837590652362658065020537815355432286385564909790828480055390241936299604049862939115284195644153207212076681276012055210262431562606172865537079398006755301921727478706141752)
+//	  legacy: 3.27e+228 (3269594370689434972246239696397784575816336438051519915677802141512029916510826903371896498585407893573079813262041191251611293391494569723115805976164312782168475127317700176973707069162557373856126234625020790537917613937349470)
+//	 vintage: 3.99e+228 (3985786980972339470046639745982013864174118310772370078099163128634390654656416392559271326021684859840974539535937081683655324261736494594244708112735908815730951142524698843844153661953325858096236390658578097533754643413523536)
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"math/rand"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/jordancurve/games/mtgcount"
+)
+
+func main() {
+	formats := flag.String("formats", "standard,modern,legacy,vintage", "comma-separated formats to count, or \"all\"")
+	numMain := flag.Int("main", 60, "number of cards in the main deck")
+	numSide := flag.Int("side", 15, "number of cards in the sideboard")
+	output := flag.String("output", "text", "output format: text, json or csv")
+	commander := flag.Bool("commander", false, "count 100-card commander decks by color identity instead")
+	sample := flag.Int("sample", 0, "print this many uniformly random legal decks of each format instead of counting")
+	var categories categoryList
+	flag.Var(&categories, "category", "only count decks whose main deck satisfies a category like type:Land=24, mv:1>=8 or \"name:Lightning Bolt>=1\"; may be repeated")
+	companion := flag.String("companion", "", "only count decks with this companion (Lurrus, Yorion, Kaheera or Gyruda) in the sideboard whose main deck satisfies its condition; for Yorion, -main is the minimum deck size and decks have 20 more")
+	history := flag.String("history", "", "path to mtgjson SetList.json or Scryfall sets: print a CSV time series of counts at every set release and ban instead")
+	bans := flag.String("bans", "", "path to a date,format,card,status CSV of ban history for -history")
+	seed := flag.Int64("seed", 0, "random seed for -sample (default: the time)")
+	approx := flag.Bool("approx", false, "print log10 of each count, approximately but with an error bound, which is much faster for large decks")
+	rules := flag.String("rules", "", "path to a JSON file of rules defining more formats, like [{\"name\": \"peasant\", \"rarities\": [\"common\", \"uncommon\"], \"banned\": [\"Sol Ring\"]}], which need Scryfall or mtgjson AllPrintings card data for rarities")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of formats to count at once")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] path/to/cards.json  # mtgjson AllCards.json, AtomicCards.json or AllPrintings.json, or Scryfall bulk data, optionally .gz or .xz\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	// Interrupting cancels the count.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	cards, err := loadCards(flag.Arg(0), *rules)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+	var counter mtgcount.Counter
+	switch {
+	case *commander:
+		err = runCommander(cards, *output)
+	case *history != "":
+		err = runHistory(cards, *formats, *history, *bans, func(limit []int) (*big.Int, error) {
+			return counter.Count(ctx, *numMain, *numSide, limit)
+		})
+	case *approx:
+		if len(categories) > 0 || *companion != "" {
+			err = fmt.Errorf("-approx doesn't support -category or -companion")
+			break
+		}
+		err = runApprox(ctx, cards, *formats, *output, *numMain, *numSide)
+	case *sample > 0:
+		if *seed == 0 {
+			*seed = time.Now().UnixNano()
+		}
+		err = runSample(cards, *formats, *numMain, *numSide, *sample, rand.New(rand.NewSource(*seed)))
+	default:
+		err = run(ctx, cards, *formats, *output, *workers, func(ctx context.Context, cards []mtgcount.Card, format string) (*big.Int, error) {
+			if *companion != "" {
+				comp, ok := mtgcount.FindCompanion(*companion)
+				if !ok {
+					return nil, fmt.Errorf("unknown companion %q", *companion)
+				}
+				return mtgcount.CountCompanionDecks(cards, format, *numMain, *numSide, comp)
+			}
+			names, limit := mtgcount.FormatCards(cards, format)
+			cats := []mtgcount.Category{}
+			for _, spec := range categories {
+				cat, err := mtgcount.ParseCategory(spec, cards, names)
+				if err != nil {
+					return nil, err
+				}
+				cats = append(cats, cat)
+			}
+			if len(cats) > 0 {
+				return mtgcount.CountDecksWith(*numMain, *numSide, limit, cats), nil
+			}
+			return counter.Count(ctx, *numMain, *numSide, limit)
+		})
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func loadCards(allCardsPath, rulesPath string) ([]mtgcount.Card, error) {
+	cards, err := mtgcount.ReadCardFileWithRules(allCardsPath, rulesPath)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(os.Stderr, "%d cards\n", len(cards))
+	return cards, nil
+}
+
+// categoryList is a flag.Value collecting repeated -category flags.
+type categoryList []string
+
+func (l *categoryList) String() string { return strings.Join(*l, " ") }
+
+func (l *categoryList) Set(spec string) error {
+	*l = append(*l, spec)
+	return nil
+}
+
+func run(ctx context.Context, cards []mtgcount.Card, formatList, output string, workers int, count func(ctx context.Context, cards []mtgcount.Card, format string) (*big.Int, error)) error {
+	formats, err := mtgcount.SelectFormats(mtgcount.Limits(cards), formatList)
+	if err != nil {
+		return err
+	}
+	counts, err := mtgcount.CountFormats(ctx, formats, workers, func(ctx context.Context, f string) (*big.Int, error) {
+		n, err := count(ctx, cards, f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", f, err)
+		}
+		return n, nil
+	})
+	if err != nil {
+		return err
+	}
+	return mtgcount.WriteCounts(os.Stdout, output, "format", counts)
+}
+
+func runApprox(ctx context.Context, cards []mtgcount.Card, formatList, output string, numMain, numSide int) error {
+	formats, err := mtgcount.SelectFormats(mtgcount.Limits(cards), formatList)
+	if err != nil {
+		return err
+	}
+	counts := []mtgcount.Approx{}
+	for _, f := range formats {
+		_, limit := mtgcount.FormatCards(cards, f)
+		log10, e, err := mtgcount.CountDecksLog10Context(ctx, numMain, numSide, limit)
+		if err != nil {
+			return err
+		}
+		counts = append(counts, mtgcount.Approx{Name: f, Log10: log10, Err: e})
+	}
+	return mtgcount.WriteApprox(os.Stdout, output, "format", counts)
+}
+
+func runCommander(cards []mtgcount.Card, output string) error {
+	total, counts := mtgcount.CountCommanderDecks(cards, 100)
+	counts = append(counts, mtgcount.Count{Name: "total", Decks: total})
+	return mtgcount.WriteCounts(os.Stdout, output, "identity", counts)
+}
+
+func runHistory(cards []mtgcount.Card, formatList, setsPath, bansPath string, count func(limit []int) (*big.Int, error)) error {
+	formats, err := mtgcount.SelectFormats(mtgcount.Limits(cards), formatList)
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(setsPath)
+	if err != nil {
+		return err
+	}
+	sets, err := mtgcount.LoadSets(data)
+	if err != nil {
+		return err
+	}
+	var bans []mtgcount.BanEvent
+	if bansPath != "" {
+		f, err := os.Open(bansPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if bans, err = mtgcount.ReadBanHistory(f); err != nil {
+			return err
+		}
+	}
+	points, err := mtgcount.History(cards, sets, bans, formats, count)
+	if err != nil {
+		return err
+	}
+	return mtgcount.WriteHistory(os.Stdout, points)
+}
+
+func runSample(cards []mtgcount.Card, formatList string, numMain, numSide, n int, r *rand.Rand) error {
+	formats, err := mtgcount.SelectFormats(mtgcount.Limits(cards), formatList)
+	if err != nil {
+		return err
+	}
+	for _, f := range formats {
+		names, limit := mtgcount.FormatCards(cards, f)
+		sm, err := mtgcount.NewSampler(numMain, numSide, names, limit)
+		if err != nil {
+			return fmt.Errorf("%s: %v", f, err)
+		}
+		for i := 0; i < n; i++ {
+			main, side := sm.Sample(r)
+			fmt.Printf("// %s\n", f)
+			printDeck(main)
+			fmt.Println("\nSideboard")
+			printDeck(side)
+			fmt.Println()
+		}
+	}
+	return nil
+}
+
+// printDeck prints a list of card names, grouped by the sampler, as
+// "count name" lines.
+func printDeck(names []string) {
+	for i := 0; i < len(names); {
+		j := i
+		for j < len(names) && names[j] == names[i] {
+			j++
+		}
+		fmt.Printf("%d %s\n", j-i, names[i])
+		i = j
+	}
+}
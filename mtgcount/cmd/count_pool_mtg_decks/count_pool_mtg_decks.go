@@ -0,0 +1,43 @@
+// Calculate the number of limited (sealed or draft) Magic the Gathering decks
+// that can be built from a card pool plus any number of basic lands, and the
+// number of distinct sideboards they leave.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/jordancurve/games/mtgcount"
+)
+
+func main() {
+	deckSize := flag.Int("deck", 40, "number of cards in the deck")
+	output := flag.String("output", "text", "output format: text, json or csv")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] pool.txt|pool.dek  # one \"count name\" line per card in the pool\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	if err := run(flag.Arg(0), *deckSize, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(poolPath string, deckSize int, output string) error {
+	data, err := ioutil.ReadFile(poolPath)
+	if err != nil {
+		return err
+	}
+	pool, err := mtgcount.ParseDeckList(data)
+	if err != nil {
+		return err
+	}
+	return mtgcount.WriteCounts(os.Stdout, output, "count", mtgcount.CountPoolDecks(pool, deckSize))
+}
@@ -0,0 +1,69 @@
+// Calculate the number of legal 60-card decks in various Magic the Gathering formats.
+//
+// Using card info from mtgjson version 4.6.3+20200508 (21045 cards):
+//
+//	standard: 2.8e+115 (28017195940711795642224306590465619699273632089594591292106829401511308382259461554677256729310446278759469882348260)
+//	  modern: 4.98e+166 (49799283634328953488916231300951000515942374393940843701494598357112083133553976290335263999751254915957305065677337567872398205625101498459743279214358537667604262540)
+//	  legacy: 1.34e+176 (133852855223192304891024530141456489373824712510430387523685941930827100356727769009091108334853139788710933440308169438342872609915064103809573318409385591561945217990022766745)
+//	 vintage: 1.57e+176 (156833188599404102378934775557412079300694483047821805614426250349773224588612121528646566293567650145703494433337572272901095738790598694925969954243159683576785212428005674360)
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"os/signal"
+	"runtime"
+
+	"github.com/jordancurve/games/mtgcount"
+)
+
+func main() {
+	formats := flag.String("formats", "standard,modern,legacy,vintage", "comma-separated formats to count, or \"all\"")
+	numCards := flag.Int("deck", 60, "number of cards in the deck")
+	output := flag.String("output", "text", "output format: text, json or csv")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of formats to count at once")
+	rules := flag.String("rules", "", "path to a JSON file of rules defining more formats, as for count_legal_mtg_decks")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] path/to/cards.json  # mtgjson AllCards.json, AtomicCards.json or AllPrintings.json, or Scryfall bulk data, optionally .gz or .xz\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	// Interrupting cancels the count.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	var counter mtgcount.Counter
+	if err := run(ctx, flag.Arg(0), *rules, *formats, *output, *workers, func(ctx context.Context, limit []int) (*big.Int, error) {
+		// LimitedMultiChoose(n, limit) is the number of decks of n cards
+		// with no sideboard.
+		return counter.Count(ctx, *numCards, 0, limit)
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, allCardsPath, rulesPath, formatList, output string, workers int, count func(ctx context.Context, limit []int) (*big.Int, error)) error {
+	cards, err := mtgcount.ReadCardFileWithRules(allCardsPath, rulesPath)
+	if err != nil {
+		return err
+	}
+	limits := mtgcount.Limits(cards)
+	formats, err := mtgcount.SelectFormats(limits, formatList)
+	if err != nil {
+		return err
+	}
+	counts, err := mtgcount.CountFormats(ctx, formats, workers, func(ctx context.Context, f string) (*big.Int, error) {
+		return count(ctx, limits[f])
+	})
+	if err != nil {
+		return err
+	}
+	return mtgcount.WriteCounts(os.Stdout, output, "format", counts)
+}
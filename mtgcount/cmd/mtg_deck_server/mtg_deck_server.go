@@ -0,0 +1,35 @@
+// Serve Magic the Gathering deck counts, format summaries, random decks and
+// deck validation over HTTP as JSON; see package server for the endpoints.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/jordancurve/games/mtgcount"
+	"github.com/jordancurve/games/mtgcount/server"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "address to listen on")
+	rules := flag.String("rules", "", "path to a JSON file of rules defining more formats, as for count_legal_mtg_decks")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] path/to/cards.json  # mtgjson AllCards.json, AtomicCards.json or AllPrintings.json, or Scryfall bulk data, optionally .gz or .xz\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	cards, err := mtgcount.ReadCardFileWithRules(flag.Arg(0), *rules)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+	log.Printf("serving %d cards on %s", len(cards), *addr)
+	log.Fatal(http.ListenAndServe(*addr, server.New(cards)))
+}
@@ -0,0 +1,135 @@
+// Calculate the exact probability that a Magic the Gathering opening hand, or
+// the first cards drawn, meet requirements like "at least 2 lands and a
+// one-drop", optionally with London mulligans.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/jordancurve/games/mtgcount"
+	"github.com/jordancurve/games/mtgcount/draw"
+)
+
+// needList is a flag.Value collecting repeated -need flags.
+type needList []string
+
+func (l *needList) String() string { return strings.Join(*l, " ") }
+
+func (l *needList) Set(spec string) error {
+	*l = append(*l, spec)
+	return nil
+}
+
+func main() {
+	var needs needList
+	flag.Var(&needs, "need", "a requirement like type:Land>=2, mv:1>=1 or \"name:Lightning Bolt=1..2\"; may be repeated")
+	numCards := flag.Int("cards", 7, "number of cards drawn: 7 for the opening hand, plus one per draw; with -mulligans, the opening hand size")
+	mulligans := flag.Int("mulligans", 0, "maximum number of London mulligans to take looking for a hand that meets the requirements")
+	format := flag.String("format", "", "if set, first check that the deck's cards are legal in this format, as validate_mtg_deck does")
+	rules := flag.String("rules", "", "path to a JSON file of rules defining more formats, as for count_legal_mtg_decks")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] path/to/cards.json deck.txt|deck.dek\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	if err := run(flag.Arg(0), *rules, flag.Arg(1), *format, needs, *numCards, *mulligans); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(allCardsPath, rulesPath, deckPath, format string, specs []string, numCards, mulligans int) error {
+	cards, err := mtgcount.ReadCardFileWithRules(allCardsPath, rulesPath)
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(deckPath)
+	if err != nil {
+		return err
+	}
+	list, err := mtgcount.ParseDeckList(data)
+	if err != nil {
+		return err
+	}
+	if format != "" {
+		if _, ok := mtgcount.Limits(cards)[format]; !ok {
+			return fmt.Errorf("unknown format %q", format)
+		}
+		// Any deck size will do; only the cards matter.
+		if vs := mtgcount.Validate(list, cards, format, 0, math.MaxInt32); len(vs) > 0 {
+			return fmt.Errorf("deck is not legal in %s: %v", format, vs)
+		}
+	}
+	// The main deck, with names as in the card data.
+	byName := map[string]string{}
+	for _, c := range cards {
+		byName[strings.ToLower(c.Name)] = c.Name
+	}
+	names, counts := []string{}, map[string]int{}
+	for _, e := range list.Main {
+		name, ok := byName[strings.ToLower(e.Name)]
+		if !ok {
+			return fmt.Errorf("unknown card %q", e.Name)
+		}
+		if _, ok := counts[name]; !ok {
+			names = append(names, name)
+		}
+		counts[name] += e.Count
+	}
+	// Cards in the same categories are one kind.
+	mask := make([]uint, len(names))
+	cats := []mtgcount.Category{}
+	for j, spec := range specs {
+		cat, err := mtgcount.ParseCategory(spec, cards, names)
+		if err != nil {
+			return err
+		}
+		for _, i := range cat.Cards {
+			mask[i] |= 1 << uint(j)
+		}
+		cats = append(cats, cat)
+	}
+	kind := map[uint]int{}
+	deck := draw.Deck{}
+	for i, name := range names {
+		k, ok := kind[mask[i]]
+		if !ok {
+			k = len(deck)
+			kind[mask[i]] = k
+			deck = append(deck, 0)
+		}
+		deck[k] += counts[name]
+	}
+	needs := []draw.Need{}
+	for j, cat := range cats {
+		need := draw.Need{Min: cat.Min, Max: cat.Max}
+		for m, k := range kind {
+			if m&(1<<uint(j)) != 0 {
+				need.Kinds = append(need.Kinds, k)
+			}
+		}
+		needs = append(needs, need)
+	}
+	var p *big.Rat
+	if mulligans > 0 {
+		p, err = draw.London(deck, numCards, needs, mulligans)
+	} else {
+		p, err = draw.Prob(deck, numCards, needs)
+	}
+	if err != nil {
+		return err
+	}
+	f, _ := p.Float64()
+	fmt.Printf("%.6f (%v)\n", f, p)
+	return nil
+}
@@ -0,0 +1,60 @@
+// Report, for Magic the Gathering formats, how many cards are legal,
+// restricted, banned and unlimited, how many are of each type, and which
+// cards' legality differs between pairs of formats, as markdown or JSON for
+// diffing across versions of the card data.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jordancurve/games/mtgcount"
+)
+
+func main() {
+	formats := flag.String("formats", "all", "comma-separated formats to report, or \"all\"")
+	diffs := flag.String("diff", "modern:pioneer,legacy:vintage", "comma-separated pairs of formats a:b to list the cards whose legality differs between")
+	output := flag.String("output", "markdown", "output format: markdown or json")
+	rules := flag.String("rules", "", "path to a JSON file of rules defining more formats, as for count_legal_mtg_decks")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] path/to/cards.json  # mtgjson AllCards.json, AtomicCards.json or AllPrintings.json, or Scryfall bulk data, optionally .gz or .xz\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	if err := run(flag.Arg(0), *rules, *formats, *diffs, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(allCardsPath, rulesPath, formatList, diffList, output string) error {
+	cards, err := mtgcount.ReadCardFileWithRules(allCardsPath, rulesPath)
+	if err != nil {
+		return err
+	}
+	limits := mtgcount.Limits(cards)
+	formats, err := mtgcount.SelectFormats(limits, formatList)
+	if err != nil {
+		return err
+	}
+	r := mtgcount.Report{Formats: mtgcount.Stats(cards, formats), Diffs: []mtgcount.FormatDiff{}}
+	if diffList != "" {
+		for _, pair := range strings.Split(diffList, ",") {
+			ab := strings.Split(pair, ":")
+			if len(ab) != 2 {
+				return fmt.Errorf("bad -diff pair %q; want a:b", pair)
+			}
+			if _, err := mtgcount.SelectFormats(limits, strings.Join(ab, ",")); err != nil {
+				return err
+			}
+			r.Diffs = append(r.Diffs, mtgcount.DiffFormats(cards, strings.TrimSpace(ab[0]), strings.TrimSpace(ab[1])))
+		}
+	}
+	return mtgcount.WriteReport(os.Stdout, output, r)
+}
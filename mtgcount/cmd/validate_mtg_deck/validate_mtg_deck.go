@@ -0,0 +1,58 @@
+// Check a Magic the Gathering deck list against the rules of a format,
+// printing every violation.  The exit status is 2 if there are any.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/jordancurve/games/mtgcount"
+)
+
+func main() {
+	format := flag.String("format", "legacy", "format to check the deck against")
+	minMain := flag.Int("main", 60, "minimum number of cards in the main deck")
+	maxSide := flag.Int("side", 15, "maximum number of cards in the sideboard")
+	rules := flag.String("rules", "", "path to a JSON file of rules defining more formats, as for count_legal_mtg_decks")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] path/to/cards.json deck.txt|deck.dek\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	violations, err := run(flag.Arg(0), *rules, flag.Arg(1), *format, *minMain, *maxSide)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+	for _, v := range violations {
+		fmt.Println(v)
+	}
+	if len(violations) > 0 {
+		os.Exit(2)
+	}
+}
+
+func run(allCardsPath, rulesPath, deckPath, format string, minMain, maxSide int) ([]mtgcount.Violation, error) {
+	cards, err := mtgcount.ReadCardFileWithRules(allCardsPath, rulesPath)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := mtgcount.Limits(cards)[format]; !ok {
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	data, err := ioutil.ReadFile(deckPath)
+	if err != nil {
+		return nil, err
+	}
+	deck, err := mtgcount.ParseDeckList(data)
+	if err != nil {
+		return nil, err
+	}
+	return mtgcount.Validate(deck, cards, format, minMain, maxSide), nil
+}
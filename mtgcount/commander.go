@@ -0,0 +1,225 @@
+package mtgcount
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// Identity is a set of colors, one bit each for W, U, B, R and G.
+type Identity uint8
+
+const colors = "WUBRG"
+
+// ParseIdentity returns the identity of a list of colors like
+// Card.ColorIdentity.
+func ParseIdentity(colorList []string) Identity {
+	var id Identity
+	for _, c := range colorList {
+		if i := strings.Index(colors, c); i >= 0 && c != "" {
+			id |= 1 << uint(i)
+		}
+	}
+	return id
+}
+
+// String returns the colors of id in WUBRG order, or "C" for colorless.
+func (id Identity) String() string {
+	if id == 0 {
+		return "C"
+	}
+	s := ""
+	for i := range colors {
+		if id&(1<<uint(i)) != 0 {
+			s += colors[i : i+1]
+		}
+	}
+	return s
+}
+
+// CommanderOption is a legal choice of one or two commanders.
+type CommanderOption struct {
+	Commanders []string
+	Identity   Identity
+}
+
+// CommanderOptions returns every legal commander and pair of commanders in
+// the commander format: legendary creatures and cards that say they can be
+// your commander, pairs of cards with partner, cards with "partner with" and
+// their named partners, and cards that let you choose a Background paired
+// with each Background.
+func CommanderOptions(cards []Card) []CommanderOption {
+	var singles, partners, backgrounds, choosers []Card
+	byName := map[string]Card{}
+	for _, c := range cards {
+		if c.Legalities["commander"] != "Legal" {
+			continue
+		}
+		byName[c.Name] = c
+		if strings.Contains(c.Type, "Background") {
+			backgrounds = append(backgrounds, c)
+			continue
+		}
+		if !(strings.Contains(c.Type, "Legendary") && strings.Contains(c.Type, "Creature")) &&
+			!strings.Contains(c.Text, "can be your commander") {
+			continue
+		}
+		singles = append(singles, c)
+		switch partner, with := partnerOf(c); {
+		case partner && with == "":
+			partners = append(partners, c)
+		case strings.Contains(c.Text, "Choose a Background"):
+			choosers = append(choosers, c)
+		}
+	}
+	options := []CommanderOption{}
+	pair := func(a, b Card) {
+		options = append(options, CommanderOption{
+			[]string{a.Name, b.Name},
+			ParseIdentity(a.ColorIdentity) | ParseIdentity(b.ColorIdentity),
+		})
+	}
+	for _, c := range singles {
+		options = append(options, CommanderOption{[]string{c.Name}, ParseIdentity(c.ColorIdentity)})
+		if _, with := partnerOf(c); with != "" && c.Name < with {
+			if d, ok := byName[with]; ok {
+				if _, back := partnerOf(d); back == c.Name {
+					pair(c, d)
+				}
+			}
+		}
+	}
+	for i, a := range partners {
+		for _, b := range partners[i+1:] {
+			pair(a, b)
+		}
+	}
+	for _, a := range choosers {
+		for _, b := range backgrounds {
+			pair(a, b)
+		}
+	}
+	return options
+}
+
+// partnerOf reports whether c has partner, and if it has "partner with",
+// the name of its partner.
+func partnerOf(c Card) (partner bool, with string) {
+	for _, line := range strings.Split(c.Text, "\n") {
+		switch {
+		case strings.HasPrefix(line, "Partner with "):
+			name := strings.TrimPrefix(line, "Partner with ")
+			if i := strings.Index(name, " ("); i >= 0 {
+				name = name[:i]
+			}
+			return true, name
+		case line == "Partner" || strings.HasPrefix(line, "Partner ("):
+			return true, ""
+		}
+	}
+	return false, ""
+}
+
+// CommanderLimit returns the number of copies of c a singleton deck can have:
+// one, unless its text allows more.
+func CommanderLimit(c Card) int {
+	if lim := CopyLimit(c); lim != 4 {
+		return lim
+	}
+	return 1
+}
+
+// singletonFormats are the formats, as Scryfall and mtgjson name them, whose
+// decks have one copy of each card besides basic lands.
+var singletonFormats = map[string]bool{
+	"commander": true, "brawl": true, "standardbrawl": true, "historicbrawl": true,
+	"duel": true, "oathbreaker": true, "paupercommander": true, "predh": true,
+	"gladiator": true,
+}
+
+// FormatLimit returns the function giving the number of copies of a card
+// legal in format a deck can have: CommanderLimit in singleton formats like
+// commander, and CopyLimit otherwise.
+func FormatLimit(format string) func(Card) int {
+	if singletonFormats[format] {
+		return CommanderLimit
+	}
+	return CopyLimit
+}
+
+// CountCommanderDecks returns the number of commander decks of deckSize
+// cards (100 in the commander format), summed over every commander option,
+// along with the number for each color identity.  Besides its commanders,
+// a deck may contain any cards legal in commander within its commanders'
+// color identity, each up to its CommanderLimit.
+func CountCommanderDecks(cards []Card, deckSize int) (*big.Int, []Count) {
+	// The pool of each identity, as the number of cards with each limit.
+	pools := map[Identity]map[int]int{}
+	pool := func(id Identity) map[int]int {
+		if p, ok := pools[id]; ok {
+			return p
+		}
+		p := map[int]int{}
+		for _, c := range cards {
+			if c.Legalities["commander"] == "Legal" && ParseIdentity(c.ColorIdentity)&^id == 0 {
+				p[CommanderLimit(c)]++
+			}
+		}
+		pools[id] = p
+		return p
+	}
+	limit := map[string]int{}
+	for _, c := range cards {
+		limit[c.Name] = CommanderLimit(c)
+	}
+	// Options with the same identity and commander limits have the same
+	// number of decks.
+	type key struct {
+		id     Identity
+		limits [2]int
+	}
+	cache := map[key]*big.Int{}
+	byIdentity := map[Identity]*big.Int{}
+	total := big.NewInt(0)
+	for _, o := range CommanderOptions(cards) {
+		k := key{id: o.Identity}
+		for i, name := range o.Commanders {
+			k.limits[i] = limit[name]
+		}
+		n, ok := cache[k]
+		if !ok {
+			p := map[int]int{}
+			for lim, count := range pool(o.Identity) {
+				p[lim] = count
+			}
+			for _, lim := range k.limits[:len(o.Commanders)] {
+				p[lim]--
+			}
+			n = countPool(p, deckSize-len(o.Commanders))
+			cache[k] = n
+		}
+		if byIdentity[o.Identity] == nil {
+			byIdentity[o.Identity] = big.NewInt(0)
+		}
+		byIdentity[o.Identity].Add(byIdentity[o.Identity], n)
+		total.Add(total, n)
+	}
+	counts := []Count{}
+	for id, n := range byIdentity {
+		counts = append(counts, Count{id.String(), n})
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i].Name < counts[j].Name })
+	return total, counts
+}
+
+// countPool returns the number of ways to choose n cards from a pool with
+// pool[L] cards that allow L copies each.
+func countPool(pool map[int]int, n int) *big.Int {
+	limits := []int{}
+	for lim, count := range pool {
+		for i := 0; i < count; i++ {
+			limits = append(limits, lim)
+		}
+	}
+	return LimitedMultiChoose(n, limits)
+}
@@ -0,0 +1,98 @@
+package mtgcount
+
+import (
+	"io/ioutil"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func loadTestCards(t *testing.T, path string) []Card {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cards, err := LoadCards(data)
+	if err != nil {
+		t.Fatalf("LoadCards(%s): %v", path, err)
+	}
+	return cards
+}
+
+func TestCommanderOptions(t *testing.T) {
+	cards := loadTestCards(t, "testdata/CommanderCards.json")
+	got := []string{}
+	for _, o := range CommanderOptions(cards) {
+		got = append(got, strings.Join(o.Commanders, " + ")+" "+o.Identity.String())
+	}
+	sort.Strings(got)
+	want := []string{
+		"Isamaru, Hound of Konda W",
+		"Pir, Imaginative Rascal + Toothy, Imaginary Friend UG",
+		"Pir, Imaginative Rascal G",
+		"Teferi, Temporal Archmage U",
+		"Thrasios, Triton Hero + Tymna the Weaver WUBG",
+		"Thrasios, Triton Hero UG",
+		"Toothy, Imaginary Friend U",
+		"Tymna the Weaver WB",
+		"Wilson, Refined Grizzly + Raised by Giants G",
+		"Wilson, Refined Grizzly G",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CommanderOptions()=%q; want %q", got, want)
+	}
+}
+
+func TestCountCommanderDecks(t *testing.T) {
+	cards := loadTestCards(t, "testdata/CommanderCards.json")
+	for _, deckSize := range []int{1, 3, 5} {
+		total, byIdentity := CountCommanderDecks(cards, deckSize)
+		// Count by brute force.
+		want := map[string]*big.Int{}
+		wantTotal := big.NewInt(0)
+		for _, o := range CommanderOptions(cards) {
+			limits := []int{}
+			for _, c := range cards {
+				if c.Legalities["commander"] == "Legal" && ParseIdentity(c.ColorIdentity)&^o.Identity == 0 &&
+					!contains(o.Commanders, c.Name) {
+					limits = append(limits, CommanderLimit(c))
+				}
+			}
+			n := big.NewInt(bruteForce(deckSize-len(o.Commanders), limits))
+			if want[o.Identity.String()] == nil {
+				want[o.Identity.String()] = big.NewInt(0)
+			}
+			want[o.Identity.String()].Add(want[o.Identity.String()], n)
+			wantTotal.Add(wantTotal, n)
+		}
+		if total.Cmp(wantTotal) != 0 {
+			t.Errorf("CountCommanderDecks(%d) total=%v; want %v", deckSize, total, wantTotal)
+		}
+		if len(byIdentity) != len(want) {
+			t.Errorf("CountCommanderDecks(%d)=%v; want %v", deckSize, byIdentity, want)
+		}
+		for _, c := range byIdentity {
+			if w := want[c.Name]; w == nil || c.Decks.Cmp(w) != 0 {
+				t.Errorf("CountCommanderDecks(%d)[%s]=%v; want %v", deckSize, c.Name, c.Decks, w)
+			}
+		}
+	}
+}
+
+// bruteForce counts the multisets of n cards with at most limits[i] copies of
+// card i by trying every number of copies of each card.
+func bruteForce(n int, limits []int) int64 {
+	if n == 0 {
+		return 1
+	}
+	if len(limits) == 0 {
+		return 0
+	}
+	sum := int64(0)
+	for k := 0; k <= limits[0] && k <= n; k++ {
+		sum += bruteForce(n-k, limits[1:])
+	}
+	return sum
+}
@@ -0,0 +1,104 @@
+package mtgcount
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// Companion is a card that, if a deck satisfies its deckbuilding condition,
+// can start the game in the sideboard and be cast from outside the game.
+type Companion struct {
+	Name string
+	// How many more cards than the minimum deck size the condition needs
+	// the main deck to have.
+	ExtraMain int
+	// Allows reports whether the condition allows c in the main deck.
+	Allows func(c Card) bool
+}
+
+// Companions lists the companions whose conditions CountCompanionDecks
+// knows.
+var Companions = []Companion{
+	// Each permanent card in your starting deck has mana value 2 or less.
+	{"Lurrus of the Dream-Den", 0, func(c Card) bool {
+		return !isPermanent(c) || c.ManaValue <= 2
+	}},
+	// Your starting deck contains at least twenty cards more than the
+	// minimum deck size.
+	{"Yorion, Sky Nomad", 20, func(c Card) bool { return true }},
+	// Each creature card in your starting deck is a Cat, Elemental,
+	// Nightmare, Dinosaur, or Beast card.
+	{"Kaheera, the Orphanguard", 0, func(c Card) bool {
+		if !contains(c.Types, "Creature") {
+			return true
+		}
+		for _, t := range []string{"Cat", "Elemental", "Nightmare", "Dinosaur", "Beast"} {
+			if contains(c.Subtypes, t) {
+				return true
+			}
+		}
+		return false
+	}},
+	// Each nonland card in your starting deck has an even mana value.
+	{"Gyruda, Doom of Depths", 0, func(c Card) bool {
+		return math.Mod(c.ManaValue, 2) == 0
+	}},
+}
+
+// FindCompanion returns the companion in Companions with the given name, or
+// the first one whose name starts with it, ignoring case.
+func FindCompanion(name string) (Companion, bool) {
+	for _, comp := range Companions {
+		if strings.HasPrefix(strings.ToLower(comp.Name), strings.ToLower(name)) {
+			return comp, true
+		}
+	}
+	return Companion{}, false
+}
+
+func isPermanent(c Card) bool {
+	for _, t := range []string{"Artifact", "Creature", "Enchantment", "Land", "Planeswalker", "Battle"} {
+		if contains(c.Types, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// CountCompanionDecks returns the number of decks legal in format, with
+// numMain cards in the main deck and numSide in the sideboard, whose
+// sideboard has comp and whose main deck satisfies comp's condition.  The
+// rest of the sideboard, including more copies of comp, is unrestricted.
+// numMain is the format's minimum deck size, so for a companion like Yorion
+// that needs a bigger deck, the main deck has comp.ExtraMain more cards.
+func CountCompanionDecks(cards []Card, format string, numMain, numSide int, comp Companion) (*big.Int, error) {
+	names, limit := FormatCards(cards, format)
+	byName := map[string]Card{}
+	for _, c := range cards {
+		byName[c.Name] = c
+	}
+	index := -1
+	disallowed := Category{}
+	for i, name := range names {
+		if name == comp.Name {
+			index = i
+		}
+		if !comp.Allows(byName[name]) {
+			disallowed.Cards = append(disallowed.Cards, i)
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("mtgcount: %s is not legal in %s", comp.Name, format)
+	}
+	if numSide < 1 {
+		return big.NewInt(0), nil
+	}
+	numMain += comp.ExtraMain
+	// Decks with the companion in the sideboard correspond to decks with
+	// one fewer sideboard card and one fewer copy of it allowed.
+	limit = append([]int{}, limit...)
+	limit[index]--
+	return CountDecksWith(numMain, numSide-1, limit, []Category{disallowed}), nil
+}
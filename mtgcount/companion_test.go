@@ -0,0 +1,119 @@
+package mtgcount
+
+import (
+	"math/big"
+	"testing"
+)
+
+func companionTestCards(t *testing.T) []Card {
+	cards := loadTestCards(t, "testdata/AtomicCards.json")
+	legal := map[string]string{"legacy": "Legal", "vintage": "Legal"}
+	return append(cards,
+		Card{Name: "Lurrus of the Dream-Den", Type: "Legendary Creature — Cat Nightmare", ManaValue: 3,
+			Types: []string{"Creature"}, Subtypes: []string{"Cat", "Nightmare"}, Legalities: legal},
+		Card{Name: "Gyruda, Doom of Depths", Type: "Legendary Creature — Demon Kraken", ManaValue: 6,
+			Types: []string{"Creature"}, Subtypes: []string{"Demon", "Kraken"}, Legalities: legal},
+	)
+}
+
+// bruteForceCompanion counts the decks of CountCompanionDecks by trying
+// every number of copies of every card.
+func bruteForceCompanion(cards []Card, format string, numMain, numSide int, comp Companion) int64 {
+	names, limit := FormatCards(cards, format)
+	byName := map[string]Card{}
+	for _, c := range cards {
+		byName[c.Name] = c
+	}
+	hasComp := false
+	var count func(i, m, s int) int64
+	count = func(i, m, s int) int64 {
+		if i == len(limit) {
+			if m == 0 && s == 0 && hasComp {
+				return 1
+			}
+			return 0
+		}
+		sum := int64(0)
+		for a := 0; a <= m; a++ {
+			if a > 0 && !comp.Allows(byName[names[i]]) {
+				break
+			}
+			for b := 0; b <= s && a+b <= limit[i]; b++ {
+				if names[i] == comp.Name {
+					hasComp = b > 0
+				}
+				sum += count(i+1, m-a, s-b)
+			}
+		}
+		return sum
+	}
+	return count(0, numMain, numSide)
+}
+
+func TestCountCompanionDecks(t *testing.T) {
+	cards := companionTestCards(t)
+	for _, name := range []string{"Lurrus", "Kaheera", "Gyruda"} {
+		comp, ok := FindCompanion(name)
+		if !ok {
+			t.Fatalf("FindCompanion(%s) not found", name)
+		}
+		if name == "Kaheera" {
+			if _, err := CountCompanionDecks(cards, "vintage", 4, 2, comp); err == nil {
+				t.Errorf("CountCompanionDecks(%s) succeeded; want error for a card not in the format", comp.Name)
+			}
+			continue
+		}
+		want := bruteForceCompanion(cards, "vintage", 4, 2, comp)
+		if got, err := CountCompanionDecks(cards, "vintage", 4, 2, comp); err != nil || got.Cmp(big.NewInt(want)) != 0 {
+			t.Errorf("CountCompanionDecks(%s, 4, 2)=%v, %v; want %d", comp.Name, got, err, want)
+		}
+	}
+}
+
+func TestCompanionConditions(t *testing.T) {
+	cards := companionTestCards(t)
+	byName := map[string]Card{}
+	for _, c := range cards {
+		byName[c.Name] = c
+	}
+	cases := []struct {
+		comp, card string
+		want       bool
+	}{
+		{"Lurrus", "Black Lotus", true},
+		{"Lurrus", "Seven Dwarves", true},
+		{"Lurrus", "Nazgûl", false},
+		{"Lurrus", "Lightning Bolt", true},
+		{"Kaheera", "Lurrus of the Dream-Den", true},
+		{"Kaheera", "Relentless Rats", false},
+		{"Kaheera", "Shock", true},
+		{"Gyruda", "Island", true},
+		{"Gyruda", "Counterspell", true},
+		{"Gyruda", "Shock", false},
+		{"Yorion", "Nazgûl", true},
+	}
+	for _, c := range cases {
+		comp, _ := FindCompanion(c.comp)
+		if got := comp.Allows(byName[c.card]); got != c.want {
+			t.Errorf("%s.Allows(%s)=%v; want %v", comp.Name, c.card, got, c.want)
+		}
+	}
+}
+
+func TestCountCompanionDecksYorion(t *testing.T) {
+	cards := append(loadTestCards(t, "testdata/AtomicCards.json"), Card{Name: "Yorion, Sky Nomad",
+		Type: "Creature — Bird Serpent", ManaValue: 5, Types: []string{"Creature"}, Legalities: map[string]string{"vintage": "Legal"}})
+	comp, _ := FindCompanion("yorion")
+	names, limit := FormatCards(cards, "vintage")
+	limit[len(names)-1]--
+	// Yorion decks have 20 more cards than the minimum.
+	for _, numMain := range []int{60, 40} {
+		want := CountDecks(numMain+20, 14, limit)
+		if got, err := CountCompanionDecks(cards, "vintage", numMain, 15, comp); err != nil || got.Cmp(want) != 0 {
+			t.Errorf("CountCompanionDecks(Yorion, %d, 15)=%v, %v; want %v", numMain, got, err, want)
+		}
+	}
+	if got, err := CountCompanionDecks(cards, "vintage", 60, 0, comp); err != nil || got.Sign() != 0 {
+		t.Errorf("CountCompanionDecks(Yorion, 60, 0)=%v, %v; want 0", got, err)
+	}
+}
@@ -0,0 +1,186 @@
+package mtgcount
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Category is a set of cards, by index into a limit list as for CountDecks,
+// of which a main deck must have between Min and Max copies in total.
+type Category struct {
+	Cards    []int
+	Min, Max int
+}
+
+// CountDecksWith is like CountDecks, but only counts decks whose main decks
+// satisfy every category.  A card may be in more than one category.
+//
+// Cards in the same categories are interchangeable, so CountDecksWith
+// multiplies out the generating function of each set of categories, then
+// combines the sets keeping track of the main deck count of each category.
+// It takes time exponential in the number of categories.
+func CountDecksWith(numMain, numSide int, limit []int, cats []Category) *big.Int {
+	if numMain < 0 || numSide < 0 {
+		return big.NewInt(0)
+	}
+	// The limits of the cards in each set of categories, as a bit mask.
+	mask := make([]uint, len(limit))
+	for j, cat := range cats {
+		for _, i := range cat.Cards {
+			mask[i] |= 1 << uint(j)
+		}
+	}
+	byMask := map[uint][]int{}
+	for i, lim := range limit {
+		byMask[mask[i]] = append(byMask[mask[i]], lim)
+	}
+	masks := []uint{}
+	for m := range byMask {
+		masks = append(masks, m)
+	}
+	sort.Slice(masks, func(i, j int) bool { return masks[i] < masks[j] })
+	// A state is the number of cards in the main deck, in the sideboard and
+	// in the main deck from each category.  Counts above a category's Max
+	// are dropped; if its Max can't be reached, counts saturate at its Min
+	// instead, which is all that matters.
+	top := make([]int, len(cats))
+	for j, cat := range cats {
+		top[j] = cat.Max
+		if cat.Max >= numMain {
+			top[j] = cat.Min
+		}
+		if top[j] < 0 {
+			return big.NewInt(0)
+		}
+	}
+	type state struct {
+		main, side int
+		cat        string // One rune per category.
+	}
+	states := map[state]*big.Int{{0, 0, string(make([]rune, len(cats)))}: big.NewInt(1)}
+	for _, m := range masks {
+		p := product(numMain, numSide, byMask[m])
+		next := map[state]*big.Int{}
+		for st, n := range states {
+			for a := 0; st.main+a <= numMain; a++ {
+				for b := 0; st.side+b <= numSide; b++ {
+					if p[a][b].Sign() == 0 {
+						continue
+					}
+					cat, ok := []rune(st.cat), true
+					for j := range cats {
+						if m&(1<<uint(j)) == 0 {
+							continue
+						}
+						c := int(cat[j]) + a
+						switch {
+						case cats[j].Max < numMain && c > top[j]:
+							ok = false
+						case c > top[j]:
+							c = top[j]
+						}
+						cat[j] = rune(c)
+					}
+					if !ok {
+						continue
+					}
+					k := state{st.main + a, st.side + b, string(cat)}
+					if next[k] == nil {
+						next[k] = new(big.Int)
+					}
+					next[k].Add(next[k], new(big.Int).Mul(n, p[a][b]))
+				}
+			}
+		}
+		states = next
+	}
+	sum := new(big.Int)
+	for st, n := range states {
+		if st.main != numMain || st.side != numSide {
+			continue
+		}
+		ok := true
+		for j, cat := range cats {
+			if int([]rune(st.cat)[j]) < cat.Min {
+				ok = false
+			}
+		}
+		if ok {
+			sum.Add(sum, n)
+		}
+	}
+	return sum
+}
+
+var categoryRE = regexp.MustCompile(`^(type|name|mv):(.+?)(=|>=|<=)(\d+)(?:\.\.(\d+))?$`)
+
+// ParseCategory returns the category described by spec, for the cards with
+// the given names as returned by FormatCards.  A spec is "type:", "name:" or
+// "mv:", then a word of the type line, like Land or Creature, a card name or
+// a mana value (of nonland cards), then "=N", ">=N", "<=N" or "=N..M".  For
+// example, "type:Land=24" is the decks with exactly 24 lands, "name:Lightning
+// Bolt>=1" those with at least one Lightning Bolt and "mv:1>=8" those with at
+// least 8 one-drops.
+func ParseCategory(spec string, cards []Card, names []string) (Category, error) {
+	m := categoryRE.FindStringSubmatch(spec)
+	if m == nil {
+		return Category{}, fmt.Errorf("mtgcount: bad category %q (want e.g. type:Land=24 or name:Lightning Bolt>=1)", spec)
+	}
+	n, _ := strconv.Atoi(m[4])
+	cat := Category{Min: n, Max: n}
+	switch {
+	case m[5] != "" && m[3] == "=":
+		cat.Max, _ = strconv.Atoi(m[5])
+	case m[5] != "":
+		return Category{}, fmt.Errorf("mtgcount: bad category %q: ranges need =", spec)
+	case m[3] == ">=":
+		cat.Max = Unlimited
+	case m[3] == "<=":
+		cat.Min = 0
+	}
+	match := func(c Card) bool { return strings.EqualFold(c.Name, m[2]) }
+	switch m[1] {
+	case "type":
+		match = func(c Card) bool { return hasType(c, m[2]) }
+	case "mv":
+		mv, err := strconv.ParseFloat(m[2], 64)
+		if err != nil {
+			return Category{}, fmt.Errorf("mtgcount: bad mana value in category %q", spec)
+		}
+		match = func(c Card) bool { return c.ManaValue == mv && !hasType(c, "Land") }
+	}
+	byName := map[string]Card{}
+	found := false
+	for _, c := range cards {
+		byName[c.Name] = c
+		found = found || match(c)
+	}
+	if m[1] == "name" && !found {
+		return Category{}, fmt.Errorf("mtgcount: unknown card %q", m[2])
+	}
+	for i, name := range names {
+		if match(byName[name]) {
+			cat.Cards = append(cat.Cards, i)
+		}
+	}
+	return cat, nil
+}
+
+// hasType reports whether a word of c's type line, before any subtypes, is
+// t, ignoring case.
+func hasType(c Card, t string) bool {
+	types := c.Type
+	if i := strings.Index(types, "—"); i >= 0 {
+		types = types[:i]
+	}
+	for _, word := range strings.Fields(types) {
+		if strings.EqualFold(word, t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package mtgcount
+
+import (
+	"math/big"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// bruteForceWith counts the decks of CountDecksWith by trying every number
+// of copies of every card.
+func bruteForceWith(numMain, numSide int, limit []int, cats []Category) int64 {
+	main, side := make([]int, len(limit)), make([]int, len(limit))
+	var count func(i, m, s int) int64
+	count = func(i, m, s int) int64 {
+		if i == len(limit) {
+			if m != 0 || s != 0 {
+				return 0
+			}
+			for _, cat := range cats {
+				n := 0
+				for _, c := range cat.Cards {
+					n += main[c]
+				}
+				if n < cat.Min || n > cat.Max {
+					return 0
+				}
+			}
+			return 1
+		}
+		sum := int64(0)
+		for a := 0; a <= m; a++ {
+			for b := 0; b <= s && a+b <= limit[i]; b++ {
+				main[i], side[i] = a, b
+				sum += count(i+1, m-a, s-b)
+			}
+		}
+		return sum
+	}
+	return count(0, numMain, numSide)
+}
+
+func TestCountDecksWith(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	choices := []int{1, 2, 4, Unlimited}
+	for i := 0; i < 100; i++ {
+		limit := make([]int, 1+r.Intn(6))
+		for j := range limit {
+			limit[j] = choices[r.Intn(len(choices))]
+		}
+		main, side := r.Intn(7), r.Intn(3)
+		cats := make([]Category, r.Intn(3))
+		for j := range cats {
+			for c := range limit {
+				if r.Intn(2) == 0 {
+					cats[j].Cards = append(cats[j].Cards, c)
+				}
+			}
+			cats[j].Min = r.Intn(4)
+			cats[j].Max = cats[j].Min + r.Intn(4)
+			if r.Intn(3) == 0 {
+				cats[j].Max = Unlimited
+			}
+		}
+		want := bruteForceWith(main, side, limit, cats)
+		if got := CountDecksWith(main, side, limit, cats); got.Cmp(big.NewInt(want)) != 0 {
+			t.Errorf("CountDecksWith(%d, %d, %v, %v)=%v; want %d", main, side, limit, cats, got, want)
+		}
+	}
+}
+
+func TestParseCategoryManaValue(t *testing.T) {
+	cards := loadTestCards(t, "testdata/AtomicCards.json")
+	cases := []struct {
+		format, spec string
+		cards        []string
+	}{
+		{"legacy", "mv:1>=8", []string{"Lightning Bolt", "Shock"}},
+		{"legacy", "mv:3=1..4", []string{"Nazgûl", "Relentless Rats"}},
+		// Island has mana value 0, but lands don't count.
+		{"legacy", "mv:0=0", []string{}},
+		{"vintage", "mv:0<=1", []string{"Black Lotus"}},
+	}
+	for _, c := range cases {
+		names, _ := FormatCards(cards, c.format)
+		cat, err := ParseCategory(c.spec, cards, names)
+		if err != nil {
+			t.Errorf("ParseCategory(%q): %v", c.spec, err)
+			continue
+		}
+		got := []string{}
+		for _, i := range cat.Cards {
+			got = append(got, names[i])
+		}
+		if !reflect.DeepEqual(got, c.cards) {
+			t.Errorf("ParseCategory(%q) in %s=%v; want %v", c.spec, c.format, got, c.cards)
+		}
+	}
+	names, _ := FormatCards(cards, "legacy")
+	if _, err := ParseCategory("mv:x=1", cards, names); err == nil {
+		t.Errorf("ParseCategory(\"mv:x=1\") succeeded; want error")
+	}
+}
+
+func TestParseCategory(t *testing.T) {
+	cards := loadTestCards(t, "testdata/AtomicCards.json")
+	names, limit := FormatCards(cards, "legacy")
+	cases := []struct {
+		spec     string
+		cards    []string
+		min, max int
+	}{
+		{"type:Land=24", []string{"Island"}, 24, 24},
+		{"type:creature<=10", []string{"Nazgûl", "Persistent Petitioners", "Relentless Rats", "Seven Dwarves"}, 0, 10},
+		{"name:Lightning Bolt>=1", []string{"Lightning Bolt"}, 1, Unlimited},
+		{"type:Instant=2..8", []string{"Counterspell", "Lightning Bolt", "Shock"}, 2, 8},
+	}
+	for _, c := range cases {
+		cat, err := ParseCategory(c.spec, cards, names)
+		if err != nil {
+			t.Errorf("ParseCategory(%q): %v", c.spec, err)
+			continue
+		}
+		got := []string{}
+		for _, i := range cat.Cards {
+			got = append(got, names[i])
+		}
+		if !reflect.DeepEqual(got, c.cards) || cat.Min != c.min || cat.Max != c.max {
+			t.Errorf("ParseCategory(%q)=%v %d..%d; want %v %d..%d", c.spec, got, cat.Min, cat.Max, c.cards, c.min, c.max)
+		}
+	}
+	for _, spec := range []string{"Land=24", "name:Lightning Blot>=1", "type:Land>=1..3"} {
+		if _, err := ParseCategory(spec, cards, names); err == nil {
+			t.Errorf("ParseCategory(%q) succeeded; want error", spec)
+		}
+	}
+	// Splitting decks by their number of lands counts every deck once.
+	sum := new(big.Int)
+	for lands := 0; lands <= 60; lands++ {
+		cat, _ := ParseCategory("type:Land="+strconv.Itoa(lands), cards, names)
+		sum.Add(sum, CountDecksWith(60, 15, limit, []Category{cat}))
+	}
+	if want := CountDecks(60, 15, limit); sum.Cmp(want) != 0 {
+		t.Errorf("sum of CountDecksWith(type:Land=L)=%v; want %v", sum, want)
+	}
+}
@@ -0,0 +1,225 @@
+package mtgcount
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DeckList is a deck as listed by a player: each entry is a number of copies
+// of a card, by name.  The commander, if any, is one of the cards of the main
+// deck, and is also listed in Commander.
+type DeckList struct {
+	Main, Side []Entry
+	Commander  []Entry
+}
+
+type Entry struct {
+	Count int
+	Name  string
+}
+
+// ParseDeckList parses a deck list in one of these formats:
+//
+//   - MTGO .dek XML files, with a Cards element per entry.
+//   - Text, with one "4 Lightning Bolt" or "4x Lightning Bolt" line per
+//     entry, as exported by most deck sites and MTGO.  Arena exports, whose
+//     lines end in a set code and collector number like "(M10) 146", work
+//     too, as do their "Commander" section, whose cards are in the main
+//     deck, and "Companion" section, whose cards are in the sideboard if
+//     it doesn't already list them.  The sideboard starts after a
+//     "Sideboard" line, or if there is no such line, after the first blank
+//     line following some cards.  Lines starting with "SB:" are in the
+//     sideboard.  A "Deck" line starts the main deck, and lines starting
+//     with "//" or "#" are ignored.
+func ParseDeckList(data []byte) (DeckList, error) {
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("<")) {
+		return parseDek(data)
+	}
+	return parseText(data)
+}
+
+func parseDek(data []byte) (DeckList, error) {
+	var dek struct {
+		Cards []struct {
+			Quantity  int    `xml:"Quantity,attr"`
+			Sideboard bool   `xml:"Sideboard,attr"`
+			Name      string `xml:"Name,attr"`
+		}
+	}
+	if err := xml.Unmarshal(data, &dek); err != nil {
+		return DeckList{}, fmt.Errorf("mtgcount: bad .dek file: %v", err)
+	}
+	deck := DeckList{}
+	for _, c := range dek.Cards {
+		e := Entry{c.Quantity, c.Name}
+		if c.Sideboard {
+			deck.Side = append(deck.Side, e)
+		} else {
+			deck.Main = append(deck.Main, e)
+		}
+	}
+	return deck, nil
+}
+
+var (
+	entryRE   = regexp.MustCompile(`^(\d+)x?\s+(.+?)(?:\s+\([0-9A-Za-z]+\)(?:\s+\S+)?)?$`)
+	sectionRE = regexp.MustCompile(`(?i)^(deck|main ?deck|sideboard|commander|companion):?$`)
+)
+
+func parseText(data []byte) (DeckList, error) {
+	deck := DeckList{}
+	// Blank lines only start the sideboard if no line does.
+	explicit := false
+	for _, line := range strings.Split(string(data), "\n") {
+		if m := sectionRE.FindStringSubmatch(strings.TrimSpace(line)); m != nil && strings.EqualFold(m[1], "sideboard") {
+			explicit = true
+		}
+	}
+	// The section the lines are in: "" for the main deck, or the lowercase
+	// header of another section.
+	section := ""
+	var companions []Entry
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case strings.HasPrefix(line, "//") || strings.HasPrefix(line, "#"):
+			continue
+		case line == "":
+			if !explicit && len(deck.Main) > len(deck.Commander) && section == "" {
+				section = "sideboard"
+			}
+			continue
+		}
+		if m := sectionRE.FindStringSubmatch(line); m != nil {
+			switch section = strings.ToLower(m[1]); section {
+			case "deck", "main deck", "maindeck":
+				section = ""
+			}
+			continue
+		}
+		inSection := section
+		if strings.HasPrefix(line, "SB:") {
+			line, inSection = strings.TrimSpace(strings.TrimPrefix(line, "SB:")), "sideboard"
+		}
+		m := entryRE.FindStringSubmatch(line)
+		if m == nil {
+			return deck, fmt.Errorf("mtgcount: deck list line %d: can't parse %q", n, line)
+		}
+		count, _ := strconv.Atoi(m[1])
+		e := Entry{count, m[2]}
+		switch inSection {
+		case "sideboard":
+			deck.Side = append(deck.Side, e)
+		case "companion":
+			companions = append(companions, e)
+		case "commander":
+			deck.Commander = append(deck.Commander, e)
+			fallthrough
+		default:
+			deck.Main = append(deck.Main, e)
+		}
+	}
+	// Arena lists the companion in the sideboard as well, when there is one.
+	for _, e := range companions {
+		listed := false
+		for _, s := range deck.Side {
+			listed = listed || strings.EqualFold(s.Name, e.Name)
+		}
+		if !listed {
+			deck.Side = append(deck.Side, e)
+		}
+	}
+	return deck, sc.Err()
+}
+
+// Violation is one way in which a deck breaks the rules of a format.
+type Violation struct {
+	Kind    ViolationKind
+	Card    string // Empty for DeckSize.
+	Message string
+}
+
+type ViolationKind int
+
+const (
+	DeckSize      ViolationKind = iota // Too few main deck or too many sideboard cards.
+	UnknownCard                        // A name that isn't in the card data.
+	NotLegal                           // A card not legal in the format, like one not yet in it.
+	Banned                             // A card banned in the format.
+	Restricted                         // More than one copy of a restricted card.
+	TooManyCopies                      // More copies than FormatLimit allows.
+)
+
+func (v Violation) String() string {
+	return v.Message
+}
+
+// Validate returns every way in which deck breaks the rules of format: a
+// main deck of at least minMain cards, a sideboard of at most maxSide cards
+// and only cards legal in the format, up to FormatLimit copies of each (one
+// if restricted) counting the main deck and sideboard together.  Card names
+// are matched ignoring case, and a split or double-faced card may be named
+// by its first face.
+func Validate(deck DeckList, cards []Card, format string, minMain, maxSide int) []Violation {
+	var vs []Violation
+	add := func(kind ViolationKind, card, format string, args ...interface{}) {
+		vs = append(vs, Violation{kind, card, fmt.Sprintf(format, args...)})
+	}
+	count := func(entries []Entry) int {
+		n := 0
+		for _, e := range entries {
+			n += e.Count
+		}
+		return n
+	}
+	if n := count(deck.Main); n < minMain {
+		add(DeckSize, "", "main deck has %d cards; want at least %d", n, minMain)
+	}
+	if n := count(deck.Side); n > maxSide {
+		add(DeckSize, "", "sideboard has %d cards; want at most %d", n, maxSide)
+	}
+	byName := map[string]Card{}
+	for _, c := range cards {
+		byName[strings.ToLower(c.Name)] = c
+		if i := strings.Index(c.Name, " // "); i >= 0 {
+			byName[strings.ToLower(c.Name[:i])] = c
+		}
+	}
+	copies := map[string]int{}
+	order := []string{}
+	for _, e := range append(append([]Entry{}, deck.Main...), deck.Side...) {
+		c, ok := byName[strings.ToLower(e.Name)]
+		if !ok {
+			add(UnknownCard, e.Name, "unknown card %q", e.Name)
+			continue
+		}
+		if _, ok := copies[c.Name]; !ok {
+			order = append(order, c.Name)
+		}
+		copies[c.Name] += e.Count
+	}
+	for _, name := range order {
+		c, n := byName[strings.ToLower(name)], copies[name]
+		switch leg := c.Legalities[format]; leg {
+		case "Legal":
+			if lim := FormatLimit(format)(c); n > lim {
+				add(TooManyCopies, name, "%d copies of %s; at most %d allowed", n, name, lim)
+			}
+		case "Restricted":
+			if n > 1 {
+				add(Restricted, name, "%d copies of %s, which is restricted in %s", n, name, format)
+			}
+		case "Banned":
+			add(Banned, name, "%s is banned in %s", name, format)
+		default:
+			add(NotLegal, name, "%s is not legal in %s", name, format)
+		}
+	}
+	return vs
+}
@@ -0,0 +1,116 @@
+package mtgcount
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestParseDeckList(t *testing.T) {
+	want := DeckList{
+		Main: []Entry{{4, "Lightning Bolt"}, {56, "Island"}},
+		Side: []Entry{{2, "Shock"}},
+	}
+	cases := []struct {
+		name, list string
+	}{
+		{"text", "4 Lightning Bolt\n56 Island\n\n2 Shock\n"},
+		{"header", "// Burn\n4x Lightning Bolt\n\n56 Island\nSideboard\n2 Shock\n"},
+		{"SB", "4 Lightning Bolt\n56 Island\nSB: 2 Shock\n"},
+		{"arena", "Deck\n4 Lightning Bolt (M10) 146\n56 Island (ZNR) 381\n\nSideboard\n2 Shock (M21) 159\n"},
+		{"dek", `<?xml version="1.0" encoding="utf-8"?>
+<Deck xmlns:xsd="http://www.w3.org/2001/XMLSchema" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+  <NetDeckID>0</NetDeckID>
+  <PreconstructedDeckID>0</PreconstructedDeckID>
+  <Cards CatID="1" Quantity="4" Sideboard="false" Name="Lightning Bolt" />
+  <Cards CatID="2" Quantity="56" Sideboard="false" Name="Island" />
+  <Cards CatID="3" Quantity="2" Sideboard="true" Name="Shock" />
+</Deck>`},
+	}
+	for _, c := range cases {
+		got, err := ParseDeckList([]byte(c.list))
+		if err != nil {
+			t.Errorf("ParseDeckList(%s): %v", c.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseDeckList(%s)=%v; want %v", c.name, got, want)
+		}
+	}
+	if _, err := ParseDeckList([]byte("Lightning Bolt\n")); err == nil {
+		t.Errorf("ParseDeckList(no count) succeeded; want error")
+	}
+}
+
+func TestParseArenaSections(t *testing.T) {
+	cases := []struct {
+		path string
+		want DeckList
+	}{
+		{"testdata/arena-companion.txt", DeckList{
+			Main: []Entry{{4, "Lightning Bolt"}, {20, "Mountain"}, {36, "Island"}},
+			Side: []Entry{{1, "Lurrus of the Dream-Den"}, {2, "Shock"}},
+		}},
+		{"testdata/arena-brawl.txt", DeckList{
+			Main:      []Entry{{1, "Niv-Mizzet, Parun"}, {4, "Lightning Bolt"}, {55, "Island"}},
+			Commander: []Entry{{1, "Niv-Mizzet, Parun"}},
+		}},
+	}
+	for _, c := range cases {
+		data, err := ioutil.ReadFile(c.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ParseDeckList(data)
+		if err != nil || !reflect.DeepEqual(got, c.want) {
+			t.Errorf("ParseDeckList(%s)=%v, %v; want %v", c.path, got, err, c.want)
+		}
+	}
+	// Without the sideboard listing it, the companion is added to it.
+	got, err := ParseDeckList([]byte("Companion\n1 Lurrus of the Dream-Den (IKO) 226\n\nDeck\n60 Island (ZNR) 381\n"))
+	want := DeckList{Main: []Entry{{60, "Island"}}, Side: []Entry{{1, "Lurrus of the Dream-Den"}}}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseDeckList(companion only)=%v, %v; want %v", got, err, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cards := loadTestCards(t, "testdata/AtomicCards.json")
+	cases := []struct {
+		list   string
+		format string
+		want   []Violation
+	}{
+		{"4 Lightning Bolt\n56 Island\n\n4 Shock\n", "legacy", nil},
+		{"4 lightning bolt\n9 Nazgûl\n47 Island\n", "modern", nil},
+		{"4 Lightning Bolt\n55 Island\n\n4 Shock\n12 Island\n", "legacy", []Violation{
+			{DeckSize, "", "main deck has 59 cards; want at least 60"},
+			{DeckSize, "", "sideboard has 16 cards; want at most 15"},
+		}},
+		{"4 Lightning Bolt\n1 Black Lotus\n55 Island\n\n1 Lightning Bolt\n", "legacy", []Violation{
+			{TooManyCopies, "Lightning Bolt", "5 copies of Lightning Bolt; at most 4 allowed"},
+			{Banned, "Black Lotus", "Black Lotus is banned in legacy"},
+		}},
+		{"2 Ancestral Recall\n1 Black Lotus\n57 Island\n", "vintage", []Violation{
+			{Restricted, "Ancestral Recall", "2 copies of Ancestral Recall, which is restricted in vintage"},
+		}},
+		{"1 Lightning Bolt\n9 Nazgûl\n7 Seven Dwarves\n43 Island\n", "commander", nil},
+		{"4 Lightning Bolt\n2 Shock\n54 Island\n", "commander", []Violation{
+			{TooManyCopies, "Lightning Bolt", "4 copies of Lightning Bolt; at most 1 allowed"},
+			{TooManyCopies, "Shock", "2 copies of Shock; at most 1 allowed"},
+		}},
+		{"4 Lightning Bolt\n4 Lightning Blot\n52 Island\n", "standard", []Violation{
+			{UnknownCard, "Lightning Blot", `unknown card "Lightning Blot"`},
+			{NotLegal, "Lightning Bolt", "Lightning Bolt is not legal in standard"},
+		}},
+	}
+	for _, c := range cases {
+		deck, err := ParseDeckList([]byte(c.list))
+		if err != nil {
+			t.Fatalf("ParseDeckList(%q): %v", c.list, err)
+		}
+		if got := Validate(deck, cards, c.format, 60, 15); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Validate(%q, %s)=%v; want %v", c.list, c.format, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Package draw computes exact probabilities of drawing combinations of cards
+// from a Magic the Gathering deck, such as the chance of an opening hand with
+// at least two lands and a one-drop.
+package draw
+
+import (
+	"errors"
+	"math/big"
+)
+
+// Deck is a deck as the number of cards of each kind.  Kinds are disjoint:
+// a card that counts as both a creature and a one-drop, say, is its own kind.
+type Deck []int
+
+// Need requires a hand to have between Min and Max cards of the given kinds.
+type Need struct {
+	Kinds    []int
+	Min, Max int
+}
+
+// NoMax is a Max that no hand reaches.
+const NoMax = 1 << 30
+
+var ErrTooManyCards = errors.New("draw: more cards than the deck has")
+
+// Size returns the number of cards in the deck.
+func (d Deck) Size() int {
+	n := 0
+	for _, c := range d {
+		n += c
+	}
+	return n
+}
+
+// hands calls f with each hand of n cards, as the number of cards of each
+// kind, and the number of ways to draw it.
+func (d Deck) hands(n int, f func(hand []int, ways *big.Int)) {
+	hand := make([]int, len(d))
+	ways := big.NewInt(1)
+	var rec func(k, left int)
+	rec = func(k, left int) {
+		if k == len(d) {
+			if left == 0 {
+				f(hand, ways)
+			}
+			return
+		}
+		saved := new(big.Int).Set(ways)
+		for h := 0; h <= left && h <= d[k]; h++ {
+			hand[k] = h
+			ways.Mul(saved, new(big.Int).Binomial(int64(d[k]), int64(h)))
+			rec(k+1, left-h)
+		}
+		ways.Set(saved)
+		hand[k] = 0
+	}
+	rec(0, n)
+}
+
+// satisfies reports whether hand has the cards every need requires.
+func satisfies(hand []int, needs []Need) bool {
+	for _, need := range needs {
+		n := 0
+		for _, k := range need.Kinds {
+			n += hand[k]
+		}
+		if n < need.Min || n > need.Max {
+			return false
+		}
+	}
+	return true
+}
+
+// Prob returns the probability that n cards drawn at random from the deck
+// satisfy every need: the multivariate hypergeometric distribution, summed
+// over the hands that qualify.
+func Prob(d Deck, n int, needs []Need) (*big.Rat, error) {
+	return probOf(d, n, func(hand []int) bool { return satisfies(hand, needs) })
+}
+
+func probOf(d Deck, n int, ok func(hand []int) bool) (*big.Rat, error) {
+	size := d.Size()
+	if n > size {
+		return nil, ErrTooManyCards
+	}
+	good := new(big.Int)
+	d.hands(n, func(hand []int, ways *big.Int) {
+		if ok(hand) {
+			good.Add(good, ways)
+		}
+	})
+	return new(big.Rat).SetFrac(good, new(big.Int).Binomial(int64(size), int64(n))), nil
+}
+
+// keepable reports whether some size cards of hand satisfy every need.
+func keepable(hand []int, size int, needs []Need) bool {
+	sub := make([]int, len(hand))
+	var rec func(k, left int) bool
+	rec = func(k, left int) bool {
+		if k == len(hand) {
+			return left == 0 && satisfies(sub, needs)
+		}
+		for h := 0; h <= left && h <= hand[k]; h++ {
+			sub[k] = h
+			if rec(k+1, left-h) {
+				return true
+			}
+		}
+		return false
+	}
+	return rec(0, size)
+}
+
+// London returns the probability of keeping a hand that satisfies every need
+// under the London mulligan: draw handSize cards, and if no handSize-k of
+// them satisfy the needs after k mulligans, shuffle and draw again, up to
+// maxMulligans times.  The player keeps the first hand that can satisfy the
+// needs, putting k cards on the bottom.
+func London(d Deck, handSize int, needs []Need, maxMulligans int) (*big.Rat, error) {
+	fail := big.NewRat(1, 1)
+	for k := 0; k <= maxMulligans && k <= handSize; k++ {
+		p, err := probOf(d, handSize, func(hand []int) bool { return keepable(hand, handSize-k, needs) })
+		if err != nil {
+			return nil, err
+		}
+		fail.Mul(fail, new(big.Rat).Sub(big.NewRat(1, 1), p))
+	}
+	return new(big.Rat).Sub(big.NewRat(1, 1), fail), nil
+}
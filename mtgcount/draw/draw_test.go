@@ -0,0 +1,54 @@
+package draw
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestProb(t *testing.T) {
+	lands := Need{[]int{0}, 2, NoMax}
+	cases := []struct {
+		deck  Deck
+		n     int
+		needs []Need
+		want  string
+	}{
+		{Deck{1, 59}, 7, []Need{{[]int{0}, 1, NoMax}}, "7/60"},
+		{Deck{1, 59}, 7, nil, "1"},
+		{Deck{24, 36}, 7, []Need{lands}, "139357/162545"},
+		{Deck{24, 8, 28}, 7, []Need{lands, {[]int{1}, 1, NoMax}}, "25944184/48275865"},
+		// Lands and one-drops together, counted as one category.
+		{Deck{4, 4}, 2, []Need{{[]int{0, 1}, 2, 2}}, "1"},
+		{Deck{4, 4}, 2, []Need{{[]int{0}, 0, 0}}, "3/14"},
+	}
+	for _, c := range cases {
+		got, err := Prob(c.deck, c.n, c.needs)
+		want, _ := new(big.Rat).SetString(c.want)
+		if err != nil || got.Cmp(want) != 0 {
+			t.Errorf("Prob(%v, %d, %v)=%v, %v; want %s", c.deck, c.n, c.needs, got, err, c.want)
+		}
+	}
+	if _, err := Prob(Deck{3, 3}, 7, nil); err != ErrTooManyCards {
+		t.Errorf("Prob(6 cards, 7)=%v; want %v", err, ErrTooManyCards)
+	}
+}
+
+func TestLondon(t *testing.T) {
+	cases := []struct {
+		deck         Deck
+		needs        []Need
+		maxMulligans int
+		want         string
+	}{
+		{Deck{1, 59}, []Need{{[]int{0}, 1, NoMax}}, 0, "7/60"},
+		{Deck{1, 59}, []Need{{[]int{0}, 1, NoMax}}, 1, "791/3600"}, // 1-(53/60)²
+		{Deck{24, 36}, []Need{{[]int{0}, 2, 4}}, 2, "12818661439613036/12883744368085875"},
+	}
+	for _, c := range cases {
+		got, err := London(c.deck, 7, c.needs, c.maxMulligans)
+		want, _ := new(big.Rat).SetString(c.want)
+		if err != nil || got.Cmp(want) != 0 {
+			t.Errorf("London(%v, 7, %v, %d)=%v, %v; want %s", c.deck, c.needs, c.maxMulligans, got, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package mtgcount
+
+import (
+	"context"
+	"math/big"
+	"sort"
+)
+
+// poly is a polynomial in x and y, truncated to degree main in x and side in
+// y.  poly[m][s] is the coefficient of x^m y^s.  In the generating function
+// of a set of cards, it is the number of ways to put m cards in the main deck
+// and s in the sideboard.
+type poly [][]*big.Int
+
+func newPoly(main, side int) poly {
+	p := make(poly, main+1)
+	for m := range p {
+		p[m] = make([]*big.Int, side+1)
+		for s := range p[m] {
+			p[m][s] = new(big.Int)
+		}
+	}
+	return p
+}
+
+// one returns the polynomial 1.
+func one(main, side int) poly {
+	p := newPoly(main, side)
+	p[0][0].SetInt64(1)
+	return p
+}
+
+// mul returns p*q, truncated.
+func (p poly) mul(q poly) poly {
+	main, side := len(p)-1, len(p[0])-1
+	type term struct {
+		m, s int
+		c    *big.Int
+	}
+	terms := []term{}
+	for m, row := range q {
+		for s, c := range row {
+			if c.Sign() != 0 {
+				terms = append(terms, term{m, s, c})
+			}
+		}
+	}
+	r := newPoly(main, side)
+	t := new(big.Int)
+	for m, row := range p {
+		for s, a := range row {
+			if a.Sign() == 0 {
+				continue
+			}
+			for _, b := range terms {
+				if m+b.m <= main && s+b.s <= side {
+					r[m+b.m][s+b.s].Add(r[m+b.m][s+b.s], t.Mul(a, b.c))
+				}
+			}
+		}
+	}
+	return r
+}
+
+// group is a set of cards that share a copy limit.
+type group struct {
+	lim   int
+	cards []int // Indexes into the limit list.
+	// powers[k] is q^k, where 1+q is the generating function of one card:
+	// q[m][s] is 1 for 0 < m+s ≤ lim.  powers[k][m][s] is the number of
+	// ways to put m cards in the main deck and s in the sideboard using each
+	// of k given cards at least once.
+	powers []poly
+	// The generating function of all the cards: (1+q)^n.
+	poly poly
+}
+
+// groups returns the cards of limit grouped by copy limit, in increasing
+// order of limit.  Limits above main+side are capped, since no deck can
+// tell them apart, and cards with a limit of 0 are left out.
+func groups(main, side int, limit []int) []*group {
+	gs, _ := groupsContext(context.Background(), main, side, limit)
+	return gs
+}
+
+// groupsContext is like groups, but stops early if ctx is done, returning
+// its error.
+func groupsContext(ctx context.Context, main, side int, limit []int) ([]*group, error) {
+	byLimit := map[int]*group{}
+	gs := []*group{}
+	for i, lim := range limit {
+		if lim > main+side {
+			lim = main + side
+		}
+		if lim <= 0 {
+			continue
+		}
+		g, ok := byLimit[lim]
+		if !ok {
+			g = &group{lim: lim}
+			byLimit[lim] = g
+			gs = append(gs, g)
+		}
+		g.cards = append(g.cards, i)
+	}
+	sort.Slice(gs, func(i, j int) bool { return gs[i].lim < gs[j].lim })
+	for _, g := range gs {
+		if err := g.expandContext(ctx, main, side); err != nil {
+			return nil, err
+		}
+	}
+	return gs, nil
+}
+
+// expand computes the powers and generating function of g.  Since q has no
+// constant term, (1+q)^n is Σ_k C(n, k) q^k, and q^k vanishes once k exceeds
+// main+side.  This is much cheaper than raising 1+q to the nth power by
+// squaring, since the coefficients of q^k stay small.
+func (g *group) expand(main, side int) {
+	g.expandContext(context.Background(), main, side)
+}
+
+// expandContext is like expand, but stops early if ctx is done, returning
+// its error.
+func (g *group) expandContext(ctx context.Context, main, side int) error {
+	n := len(g.cards)
+	q := newPoly(main, side)
+	for m := 0; m <= main; m++ {
+		for s := 0; s <= side && m+s <= g.lim; s++ {
+			if m+s > 0 {
+				q[m][s].SetInt64(1)
+			}
+		}
+	}
+	g.powers = []poly{one(main, side)}
+	g.poly = one(main, side)
+	c := new(big.Int)
+	for k := 1; k <= n && k <= main+side; k++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		qk := g.powers[k-1].mul(q)
+		g.powers = append(g.powers, qk)
+		c.Binomial(int64(n), int64(k))
+		for m, row := range qk {
+			for s, a := range row {
+				if a.Sign() != 0 {
+					g.poly[m][s].Add(g.poly[m][s], new(big.Int).Mul(c, a))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// countDecksGF computes CountDecks from the generating function of the
+// cards, which depends only on how many cards share each limit.
+func countDecksGF(numMain, numSide int, limit []int) *big.Int {
+	if numMain < 0 || numSide < 0 {
+		return big.NewInt(0)
+	}
+	return product(numMain, numSide, limit)[numMain][numSide]
+}
+
+// product returns the generating function of the cards with the given
+// limits.
+func product(main, side int, limit []int) poly {
+	p := one(main, side)
+	for _, g := range groups(main, side, limit) {
+		p = p.mul(g.poly)
+	}
+	return p
+}
@@ -0,0 +1,55 @@
+package mtgcount
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestCountDecksGF(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	choices := []int{0, 1, 2, 3, 4, 7, Unlimited}
+	for i := 0; i < 200; i++ {
+		limit := make([]int, r.Intn(12))
+		for j := range limit {
+			limit[j] = choices[r.Intn(len(choices))]
+		}
+		main, side := r.Intn(10), r.Intn(5)
+		if got, want := countDecksGF(main, side, limit), countDecks(main, side, limit); got.Cmp(want) != 0 {
+			t.Errorf("countDecksGF(%d, %d, %v)=%v; want %v", main, side, limit, got, want)
+		}
+		if got, want := LimitedMultiChoose(main, limit), limitedMultiChoose(main, limit); got.Cmp(want) != 0 {
+			t.Errorf("LimitedMultiChoose(%d, %v)=%v; want %v", main, limit, got, want)
+		}
+	}
+}
+
+// benchLimits returns limits like those of a large format: mostly cards
+// allowing 4 copies, a few restricted and a few unlimited.
+func benchLimits() []int {
+	limit := make([]int, 20000)
+	for i := range limit {
+		switch {
+		case i < 50:
+			limit[i] = 1
+		case i < 60:
+			limit[i] = Unlimited
+		default:
+			limit[i] = 4
+		}
+	}
+	return limit
+}
+
+func BenchmarkCountDecks(b *testing.B) {
+	limit := benchLimits()
+	for i := 0; i < b.N; i++ {
+		CountDecks(60, 15, limit)
+	}
+}
+
+func BenchmarkCountDecksDP(b *testing.B) {
+	limit := benchLimits()[:2000]
+	for i := 0; i < b.N; i++ {
+		countDecks(60, 15, limit)
+	}
+}
@@ -0,0 +1,193 @@
+package mtgcount
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LoadSets returns the release date, as YYYY-MM-DD, of each set in set data
+// from mtgjson (SetList.json) or Scryfall (the sets API), by set code.
+func LoadSets(data []byte) (map[string]string, error) {
+	var list struct {
+		Data []struct {
+			Code        string
+			ReleaseDate string // mtgjson
+			ReleasedAt  string `json:"released_at"` // Scryfall
+		}
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("mtgcount: reading sets: %v", err)
+	}
+	sets := map[string]string{}
+	for _, s := range list.Data {
+		date := s.ReleaseDate
+		if date == "" {
+			date = s.ReleasedAt
+		}
+		if date != "" {
+			sets[strings.ToUpper(s.Code)] = date
+		}
+	}
+	return sets, nil
+}
+
+// BanEvent is a change to the legality of a card in a format on a date.
+type BanEvent struct {
+	Date, Format, Card, Status string
+}
+
+// ReadBanHistory reads a CSV file of BanEvents, with a "date,format,card,status"
+// header.  Dates are YYYY-MM-DD, and statuses are Legal, Restricted or Banned.
+func ReadBanHistory(r io.Reader) ([]BanEvent, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 || strings.Join(rows[0], ",") != "date,format,card,status" {
+		return nil, fmt.Errorf("mtgcount: ban history must start with a date,format,card,status header")
+	}
+	events := []BanEvent{}
+	for i, row := range rows[1:] {
+		e := BanEvent{row[0], row[1], row[2], normalizeLegalities(map[string]string{"": row[3]})[""]}
+		if e.Status == "" {
+			return nil, fmt.Errorf("mtgcount: ban history line %d: bad status %q", i+2, row[3])
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// HistoryPoint is the number of decks in each format on a date.
+type HistoryPoint struct {
+	Date   string
+	Counts []Count
+}
+
+// History returns the number of decks, as computed by count from a limit
+// list, or count's first error, in each of the given formats on every date a relevant set was
+// released or a ban event happened.  On a date, a format's cards are those
+// legal, restricted or banned in it now that were first printed by then.
+// A card's status is that of its last ban event so far; before its first
+// event it is legal, and without any events it has its current status.
+//
+// Since the card data only says which formats a card is in now, formats
+// that rotate, like standard, only include the cards they have now.  The
+// card data must have every card's first printing, as mtgjson data and
+// Scryfall default cards do, but Scryfall oracle cards don't.
+func History(cards []Card, sets map[string]string, bans []BanEvent, formats []string, count func(limit []int) (*big.Int, error)) ([]HistoryPoint, error) {
+	type key struct{ format, card string }
+	events := map[key][]BanEvent{}
+	dates := map[string]bool{}
+	for _, e := range bans {
+		events[key{e.Format, e.Card}] = append(events[key{e.Format, e.Card}], e)
+		dates[e.Date] = true
+	}
+	for _, es := range events {
+		sort.SliceStable(es, func(i, j int) bool { return es[i].Date < es[j].Date })
+	}
+	// The date each card was first printed.
+	first := make([]string, len(cards))
+	for i, c := range cards {
+		if c.OnlyReprints {
+			return nil, fmt.Errorf("mtgcount: %s: the card data lacks its first printing; use mtgjson data or Scryfall default cards", c.Name)
+		}
+		for _, p := range c.Printings {
+			date, ok := sets[p]
+			if !ok {
+				return nil, fmt.Errorf("mtgcount: %s: unknown set %s", c.Name, p)
+			}
+			if first[i] == "" || date < first[i] {
+				first[i] = date
+			}
+		}
+		if first[i] != "" {
+			dates[first[i]] = true
+		}
+	}
+	sorted := []string{}
+	for d := range dates {
+		sorted = append(sorted, d)
+	}
+	sort.Strings(sorted)
+	// Counts depend only on how many cards have each limit.
+	cache := map[string]*big.Int{}
+	points := []HistoryPoint{}
+	for _, date := range sorted {
+		point := HistoryPoint{Date: date}
+		for _, f := range formats {
+			limit := []int{}
+			for i, c := range cards {
+				status, ok := c.Legalities[f]
+				if !ok || first[i] == "" || first[i] > date {
+					continue
+				}
+				if es := events[key{f, c.Name}]; len(es) > 0 {
+					status = "Legal"
+					for _, e := range es {
+						if e.Date <= date {
+							status = e.Status
+						}
+					}
+				}
+				switch status {
+				case "Legal":
+					limit = append(limit, FormatLimit(f)(c))
+				case "Restricted":
+					limit = append(limit, 1)
+				}
+			}
+			k := limitsKey(limit)
+			n, ok := cache[k]
+			if !ok {
+				var err error
+				if n, err = count(limit); err != nil {
+					return nil, err
+				}
+				cache[k] = n
+			}
+			point.Counts = append(point.Counts, Count{f, n})
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+// limitsKey returns a string that identifies the multiset of limits.
+func limitsKey(limit []int) string {
+	sorted := append([]int{}, limit...)
+	sort.Ints(sorted)
+	var k strings.Builder
+	for _, lim := range sorted {
+		k.WriteString(strconv.Itoa(lim))
+		k.WriteByte(' ')
+	}
+	return k.String()
+}
+
+// WriteHistory writes points to w as CSV, with a "date" column and one
+// column per format.
+func WriteHistory(w io.Writer, points []HistoryPoint) error {
+	cw := csv.NewWriter(w)
+	if len(points) > 0 {
+		header := []string{"date"}
+		for _, c := range points[0].Counts {
+			header = append(header, c.Name)
+		}
+		cw.Write(header)
+	}
+	for _, p := range points {
+		row := []string{p.Date}
+		for _, c := range p.Counts {
+			row = append(row, c.Decks.String())
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,132 @@
+package mtgcount
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadSets(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/SetList.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sets, err := LoadSets(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sets) != 8 || sets["LEA"] != "1993-08-05" || sets["LTR"] != "2023-06-23" {
+		t.Errorf("LoadSets(testdata/SetList.json)=%v; want 8 sets with LEA 1993-08-05", sets)
+	}
+	scryfall := `{"object": "list", "data": [{"object": "set", "code": "m10", "released_at": "2009-07-17"}]}`
+	if sets, err := LoadSets([]byte(scryfall)); err != nil || !reflect.DeepEqual(sets, map[string]string{"M10": "2009-07-17"}) {
+		t.Errorf("LoadSets(scryfall)=%v, %v; want M10 2009-07-17", sets, err)
+	}
+}
+
+func TestReadBanHistory(t *testing.T) {
+	if _, err := ReadBanHistory(strings.NewReader("date,format,card,status\n2020-01-01,modern,Shock,Suspended\n")); err == nil {
+		t.Errorf("ReadBanHistory(bad status) succeeded; want error")
+	}
+	if _, err := ReadBanHistory(strings.NewReader("2020-01-01,modern,Shock,Banned\n")); err == nil {
+		t.Errorf("ReadBanHistory(no header) succeeded; want error")
+	}
+}
+
+func TestHistory(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/SetList.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sets, err := LoadSets(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open("testdata/bans.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	bans, err := ReadBanHistory(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Count 100 per card, plus 1 per restricted card.
+	count := func(limit []int) (*big.Int, error) {
+		n := int64(100 * len(limit))
+		for _, lim := range limit {
+			if lim == 1 {
+				n++
+			}
+		}
+		return big.NewInt(n), nil
+	}
+	want := `date,vintage,legacy,modern
+1993-08-05,500,500,200
+1993-08-25,501,500,200
+1994-01-10,502,500,200
+1998-03-02,602,600,300
+2003-10-02,702,700,300
+2004-09-20,702,500,300
+2011-08-12,702,500,400
+2018-07-13,802,600,500
+2019-10-04,902,700,600
+2023-06-23,1002,800,700
+`
+	for _, path := range []string{"testdata/AtomicCards.json", "testdata/oracle-cards.json"} {
+		points, err := History(loadTestCards(t, path), sets, bans, []string{"vintage", "legacy", "modern"}, count)
+		if err != nil {
+			t.Fatalf("History(%s): %v", path, err)
+		}
+		var buf bytes.Buffer
+		if err := WriteHistory(&buf, points); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != want {
+			t.Errorf("History(%s)=\n%s\nwant\n%s", path, got, want)
+		}
+	}
+	// Like Scryfall oracle cards, keep only the last printing of each card,
+	// which dates reprinted cards to their reprints.
+	var printings []map[string]interface{}
+	if data, err = ioutil.ReadFile("testdata/oracle-cards.json"); err == nil {
+		err = json.Unmarshal(data, &printings)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := map[string]int{}
+	for i, p := range printings {
+		last[p["name"].(string)] = i
+	}
+	oracle := []map[string]interface{}{}
+	for i, p := range printings {
+		if last[p["name"].(string)] == i {
+			oracle = append(oracle, p)
+		}
+	}
+	data, _ = json.Marshal(oracle)
+	cards, err := LoadCards(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := History(cards, sets, bans, []string{"vintage"}, count); err == nil || !strings.Contains(err.Error(), "first printing") {
+		t.Errorf("History(oracle cards)=%v; want an error about first printings", err)
+	}
+	cards = loadTestCards(t, "testdata/AtomicCards.json")
+	if _, err := History(cards, sets, bans, []string{"vintage"}, func([]int) (*big.Int, error) {
+		return nil, context.Canceled
+	}); err != context.Canceled {
+		t.Errorf("History(cancelled count)=%v; want %v", err, context.Canceled)
+	}
+	delete(sets, "LTR")
+	if _, err := History(cards, sets, bans, []string{"vintage"}, count); err == nil {
+		t.Errorf("History(unknown set) succeeded; want error")
+	}
+}
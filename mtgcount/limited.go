@@ -0,0 +1,55 @@
+package mtgcount
+
+import (
+	"math/big"
+)
+
+// BasicLands are the lands a limited player can add to a deck in any number.
+var BasicLands = []string{"Plains", "Island", "Swamp", "Mountain", "Forest"}
+
+// PoolLimits returns the names of the cards a limited deck can use, and how
+// many copies of each: the cards in pool, main deck and sideboard alike, up
+// to their number in the pool, and any number of BasicLands.
+func PoolLimits(pool DeckList) (names []string, limit []int) {
+	index := map[string]int{}
+	for _, e := range append(append([]Entry{}, pool.Main...), pool.Side...) {
+		i, ok := index[e.Name]
+		if !ok {
+			i = len(names)
+			index[e.Name] = i
+			names, limit = append(names, e.Name), append(limit, 0)
+		}
+		limit[i] += e.Count
+	}
+	for _, name := range BasicLands {
+		if i, ok := index[name]; ok {
+			limit[i] = Unlimited
+			continue
+		}
+		names, limit = append(names, name), append(limit, Unlimited)
+	}
+	return names, limit
+}
+
+// CountPoolDecks returns the number of limited decks of deckSize cards that
+// can be built from pool, and the number of distinct sideboards they leave.
+// The sideboard is the rest of the pool, so it depends only on the cards of
+// the deck that aren't basic lands, of which there may be any number up to
+// deckSize.
+func CountPoolDecks(pool DeckList, deckSize int) []Count {
+	names, limit := PoolLimits(pool)
+	var nonbasic []int
+	for i, name := range names {
+		if !contains(BasicLands, name) {
+			nonbasic = append(nonbasic, limit[i])
+		}
+	}
+	sideboards := new(big.Int)
+	for n := 0; n <= deckSize; n++ {
+		sideboards.Add(sideboards, LimitedMultiChoose(n, nonbasic))
+	}
+	return []Count{
+		{"decks", LimitedMultiChoose(deckSize, limit)},
+		{"sideboards", sideboards},
+	}
+}
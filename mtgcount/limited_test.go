@@ -0,0 +1,44 @@
+package mtgcount
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestPoolLimits(t *testing.T) {
+	pool, err := ParseDeckList([]byte("2 Shock\n1 Lightning Bolt\n1 Island\nSideboard\n1 Shock\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names, limit := PoolLimits(pool)
+	wantNames := []string{"Shock", "Lightning Bolt", "Island", "Plains", "Swamp", "Mountain", "Forest"}
+	wantLimit := []int{3, 1, Unlimited, Unlimited, Unlimited, Unlimited, Unlimited}
+	if !reflect.DeepEqual(names, wantNames) || !reflect.DeepEqual(limit, wantLimit) {
+		t.Errorf("PoolLimits(pool)=%v, %v; want %v, %v", names, limit, wantNames, wantLimit)
+	}
+}
+
+func TestCountPoolDecks(t *testing.T) {
+	cases := []struct {
+		pool              string
+		deckSize          int
+		decks, sideboards int64
+	}{
+		// Shock-Bolt splits of 0 to 3 cards are 1, 2, 2 and 1 ways, and the
+		// rest of 3 cards is basics: C(7,4)+2·C(6,4)+2·C(5,4)+1 = 76.
+		{"2 Shock\n1 Lightning Bolt\n", 3, 76, 6},
+		{"", 40, 135751, 1},
+		{"20 Shock\n", 40, LimitedMultiChoose(40, []int{20, Unlimited, Unlimited, Unlimited, Unlimited, Unlimited}).Int64(), 21},
+	}
+	for _, c := range cases {
+		pool, err := ParseDeckList([]byte(c.pool))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []Count{{"decks", big.NewInt(c.decks)}, {"sideboards", big.NewInt(c.sideboards)}}
+		if got := CountPoolDecks(pool, c.deckSize); !reflect.DeepEqual(got, want) {
+			t.Errorf("CountPoolDecks(%q, %d)=%v; want %v", c.pool, c.deckSize, got, want)
+		}
+	}
+}
@@ -0,0 +1,429 @@
+package mtgcount
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// A Loader reads cards from one card-data format, streaming them from a
+// json.Decoder so that only the fields of Card are ever kept in memory.
+// Detect reports whether data starting with the delimiter first ('{' or '[')
+// and, if it's an object, the key key looks like the format.  Read reads the
+// rest of the data: the value of key and the rest of the object, or the
+// elements of the array and its closing bracket.
+type Loader struct {
+	Name   string
+	Detect func(first json.Delim, key string) bool
+	Read   func(dec *json.Decoder, key string) ([]Card, error)
+}
+
+// Loaders lists the card-data formats ReadCards knows, in the order it tries
+// them.
+var Loaders = []Loader{
+	{"mtgjson v5", isMTGJSONv5, readMTGJSONv5},
+	{"scryfall", isScryfall, readScryfall},
+	{"mtgjson v4", isMTGJSONv4, readMTGJSONv4},
+}
+
+var ErrUnknownFormat = errors.New("mtgcount: unknown card data format")
+
+// LoadCards parses card data in any of the formats in Loaders, returning
+// the cards sorted by name.  Legalities are normalized to mtgjson's "Legal",
+// "Restricted" and "Banned"; formats in which a card is not legal are left
+// out.
+func LoadCards(data []byte) ([]Card, error) {
+	return ReadCards(bytes.NewReader(data))
+}
+
+// ReadCards is like LoadCards, but streams the card data from r, which may
+// be compressed with gzip or xz.  Reading xz needs the xz command.
+func ReadCards(r io.Reader) ([]Card, error) {
+	r, closer, err := decompress(r)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	first, ok := tok.(json.Delim)
+	if err != nil || (first != '{' && first != '[') || !ok {
+		return nil, ErrUnknownFormat
+	}
+	key := ""
+	if first == '{' {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, ErrUnknownFormat
+		}
+		if key, ok = tok.(string); !ok {
+			// An empty object.
+			return []Card{}, nil
+		}
+	}
+	for _, l := range Loaders {
+		if !l.Detect(first, key) {
+			continue
+		}
+		cards, err := l.Read(dec, key)
+		if err != nil {
+			return nil, fmt.Errorf("mtgcount: reading %s: %v", l.Name, err)
+		}
+		if err := closer.Close(); err != nil {
+			return nil, err
+		}
+		sort.Slice(cards, func(i, j int) bool { return cards[i].Name < cards[j].Name })
+		return cards, nil
+	}
+	return nil, ErrUnknownFormat
+}
+
+// ReadCardFile reads the cards in the file at path, as ReadCards does.
+func ReadCardFile(path string) ([]Card, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadCards(f)
+}
+
+// xzCommand is the command that ReadCards decompresses xz with.
+var xzCommand = "xz"
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	xzMagic   = []byte{0xfd, '7', 'z', 'X', 'Z', 0}
+)
+
+// decompress returns a reader of the decompressed contents of r, if r is
+// compressed, and a Closer that releases its resources, reporting any error
+// decompressing.
+func decompress(r io.Reader) (io.Reader, io.Closer, error) {
+	br := bufio.NewReaderSize(r, 1<<16)
+	magic, _ := br.Peek(len(xzMagic))
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, nil, err
+		}
+		return zr, zr, nil
+	case bytes.HasPrefix(magic, xzMagic):
+		path, err := exec.LookPath(xzCommand)
+		if err != nil {
+			return nil, nil, fmt.Errorf("mtgcount: reading xz needs the %s command: %v", xzCommand, err)
+		}
+		cmd := exec.Command(path, "--decompress", "--stdout")
+		cmd.Stdin = br
+		out, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, nil, fmt.Errorf("mtgcount: reading xz: %v", err)
+		}
+		return out, &xzCloser{cmd: cmd, out: out}, nil
+	}
+	return br, ioutil.NopCloser(nil), nil
+}
+
+// xzCloser waits for the xz command, at most once.
+type xzCloser struct {
+	cmd  *exec.Cmd
+	out  io.Reader
+	done bool
+	err  error
+}
+
+func (c *xzCloser) Close() error {
+	if !c.done {
+		c.done = true
+		// Drain the output, which xz may be blocked writing.
+		io.Copy(ioutil.Discard, c.out)
+		if err := c.cmd.Wait(); err != nil {
+			c.err = fmt.Errorf("mtgcount: reading xz: %v", err)
+		}
+	}
+	return c.err
+}
+
+// readObject calls f with each key of a JSON object until its closing brace,
+// f having decoded the key's value.  The first key has already been read.
+func readObject(dec *json.Decoder, key string, f func(key string) error) error {
+	for {
+		if err := f(key); err != nil {
+			return err
+		}
+		if !dec.More() {
+			_, err := dec.Token()
+			return err
+		}
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ = tok.(string)
+	}
+}
+
+func normalizeLegalities(legalities map[string]string) map[string]string {
+	norm := map[string]string{}
+	for f, leg := range legalities {
+		switch strings.ToLower(leg) {
+		case "legal":
+			norm[f] = "Legal"
+		case "restricted":
+			norm[f] = "Restricted"
+		case "banned":
+			norm[f] = "Banned"
+		}
+	}
+	return norm
+}
+
+// mtgjson v4 AllCards.json: {name: card, ...}.
+
+func isMTGJSONv4(first json.Delim, key string) bool {
+	return first == '{'
+}
+
+// mtgjsonCard is a card as mtgjson has it.  Before v5, the mana value was
+// called converted mana cost.
+type mtgjsonCard struct {
+	Card
+	ConvertedManaCost float64
+}
+
+func (c mtgjsonCard) card() Card {
+	if c.ManaValue == 0 {
+		c.ManaValue = c.ConvertedManaCost
+	}
+	c.Legalities = normalizeLegalities(c.Legalities)
+	return c.Card
+}
+
+func readMTGJSONv4(dec *json.Decoder, key string) ([]Card, error) {
+	list := []Card{}
+	err := readObject(dec, key, func(string) error {
+		var c mtgjsonCard
+		if err := dec.Decode(&c); err != nil {
+			return err
+		}
+		list = append(list, c.card())
+		return nil
+	})
+	return list, err
+}
+
+// mtgjson v5 AtomicCards.json: {"meta": {...}, "data": {name: [face, ...], ...}},
+// or AllPrintings.json: {"meta": {...}, "data": {set code: {"cards": [printing, ...], ...}, ...}}.
+
+func isMTGJSONv5(first json.Delim, key string) bool {
+	return first == '{' && (key == "meta" || key == "data")
+}
+
+func readMTGJSONv5(dec *json.Decoder, key string) ([]Card, error) {
+	list := []Card{}
+	err := readObject(dec, key, func(key string) error {
+		if key != "data" {
+			var skip json.RawMessage
+			return dec.Decode(&skip)
+		}
+		if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+			return fmt.Errorf("data is not an object")
+		}
+		if !dec.More() {
+			_, err := dec.Token()
+			return err
+		}
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		name, _ := tok.(string)
+		index := map[string]int{}
+		return readObject(dec, name, func(name string) error {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			if tok == json.Delim('{') {
+				return readSet(dec, &list, index)
+			}
+			var faces []mtgjsonCard
+			for dec.More() {
+				var face mtgjsonCard
+				if err := dec.Decode(&face); err != nil {
+					return err
+				}
+				faces = append(faces, face)
+			}
+			if _, err := dec.Token(); err != nil || len(faces) == 0 {
+				return err
+			}
+			// Every face of a card has the same legalities.
+			c := faces[0].card()
+			c.Name = name
+			list = append(list, c)
+			return nil
+		})
+	})
+	return list, err
+}
+
+// mtgjsonPrinting is a card in a set of AllPrintings.json.
+type mtgjsonPrinting struct {
+	mtgjsonCard
+	Rarity string
+}
+
+// readSet reads the rest of a set of AllPrintings.json, merging the
+// printings of its cards into those of list, indexed by name.
+func readSet(dec *json.Decoder, list *[]Card, index map[string]int) error {
+	if !dec.More() {
+		_, err := dec.Token()
+		return err
+	}
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	key, _ := tok.(string)
+	return readObject(dec, key, func(key string) error {
+		if key != "cards" {
+			var skip json.RawMessage
+			return dec.Decode(&skip)
+		}
+		if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+			return fmt.Errorf("cards is not an array")
+		}
+		for dec.More() {
+			var p mtgjsonPrinting
+			if err := dec.Decode(&p); err != nil {
+				return err
+			}
+			i, ok := index[p.Name]
+			if !ok {
+				i = len(*list)
+				index[p.Name] = i
+				*list = append(*list, p.card())
+			}
+			if c := &(*list)[i]; p.Rarity != "" && !contains(c.Rarities, p.Rarity) {
+				c.Rarities = append(c.Rarities, p.Rarity)
+			}
+		}
+		_, err := dec.Token()
+		return err
+	})
+}
+
+// Scryfall bulk data: [card, ...].  Oracle cards has one entry per card;
+// default cards has one per printing, which are merged.
+
+type scryfallCard struct {
+	Name          string
+	TypeLine      string   `json:"type_line"`
+	OracleText    string   `json:"oracle_text"`
+	ColorIdentity []string `json:"color_identity"`
+	Colors        []string
+	CMC           float64
+	Layout        string
+	Legalities    map[string]string
+	Set           string
+	Rarity        string
+	Reprint       bool
+}
+
+func isScryfall(first json.Delim, key string) bool {
+	return first == '['
+}
+
+func readScryfall(dec *json.Decoder, key string) ([]Card, error) {
+	list := []Card{}
+	index := map[string]int{}
+	for dec.More() {
+		var c scryfallCard
+		if err := dec.Decode(&c); err != nil {
+			return nil, err
+		}
+		switch c.Layout {
+		case "token", "double_faced_token", "emblem", "art_series":
+			continue
+		}
+		set := strings.ToUpper(c.Set)
+		if i, ok := index[c.Name]; ok {
+			if set != "" && !contains(list[i].Printings, set) {
+				list[i].Printings = append(list[i].Printings, set)
+			}
+			if c.Rarity != "" && !contains(list[i].Rarities, c.Rarity) {
+				list[i].Rarities = append(list[i].Rarities, c.Rarity)
+			}
+			list[i].OnlyReprints = list[i].OnlyReprints && c.Reprint
+			continue
+		}
+		card := Card{
+			Name:          c.Name,
+			Type:          c.TypeLine,
+			Text:          c.OracleText,
+			Legalities:    normalizeLegalities(c.Legalities),
+			ColorIdentity: c.ColorIdentity,
+			ManaValue:     c.CMC,
+			Colors:        c.Colors,
+			OnlyReprints:  c.Reprint,
+		}
+		card.Types, card.Subtypes = parseTypeLine(c.TypeLine)
+		if set != "" {
+			card.Printings = []string{set}
+		}
+		if c.Rarity != "" {
+			card.Rarities = []string{c.Rarity}
+		}
+		index[c.Name] = len(list)
+		list = append(list, card)
+	}
+	_, err := dec.Token()
+	return list, err
+}
+
+// supertypes are the words of a type line that are neither types nor
+// subtypes.
+var supertypes = []string{"Basic", "Legendary", "Ongoing", "Snow", "World", "Token"}
+
+// parseTypeLine returns the types and subtypes of a type line like
+// "Legendary Creature — Elf Druid".  For a card with several faces, it
+// returns those of the first face.
+func parseTypeLine(line string) (types, subtypes []string) {
+	if i := strings.Index(line, " // "); i >= 0 {
+		line = line[:i]
+	}
+	parts := strings.SplitN(line, "—", 2)
+	types, subtypes = []string{}, []string{}
+	for _, t := range strings.Fields(parts[0]) {
+		if !contains(supertypes, t) {
+			types = append(types, t)
+		}
+	}
+	if len(parts) == 2 {
+		subtypes = strings.Fields(parts[1])
+	}
+	return types, subtypes
+}
+
+func contains(list []string, s string) bool {
+	for _, t := range list {
+		if t == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,239 @@
+package mtgcount
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadCards(t *testing.T) {
+	var want []Card
+	for _, path := range []string{"testdata/AllCards.json", "testdata/AtomicCards.json", "testdata/oracle-cards.json", "testdata/AllPrintings.json"} {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := LoadCards(data)
+		if err != nil {
+			t.Errorf("LoadCards(%s): %v", path, err)
+			continue
+		}
+		// Only data with printings has rarities.
+		if hasRarities := path == "testdata/oracle-cards.json" || path == "testdata/AllPrintings.json"; hasRarities == (got[2].Rarities == nil) {
+			t.Errorf("LoadCards(%s)[2].Rarities=%v", path, got[2].Rarities)
+		} else if got[2].Rarities != nil && !reflect.DeepEqual(got[2].Rarities, []string{"uncommon", "common"}) {
+			t.Errorf("LoadCards(%s)[2].Rarities=%v; want Counterspell's [uncommon common]", path, got[2].Rarities)
+		}
+		for i := range got {
+			got[i].Rarities = nil
+		}
+		if want == nil {
+			want = got
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("LoadCards(%s)=%v; want %v", path, got, want)
+		}
+	}
+	if len(want) != 10 || want[0].Name != "Ancestral Recall" {
+		t.Fatalf("LoadCards(testdata/AllCards.json)=%v; want 10 cards sorted by name", want)
+	}
+	nazgul := want[5]
+	if nazgul.ManaValue != 3 || !reflect.DeepEqual(nazgul.Types, []string{"Creature"}) ||
+		!reflect.DeepEqual(nazgul.Subtypes, []string{"Wraith", "Knight"}) || !reflect.DeepEqual(nazgul.Colors, []string{"B"}) {
+		t.Errorf("LoadCards(testdata/AllCards.json)[5]=%+v; want Nazgûl, a 3-mana black Wraith Knight creature", nazgul)
+	}
+}
+
+func TestParseTypeLine(t *testing.T) {
+	cases := []struct {
+		line            string
+		types, subtypes []string
+	}{
+		{"Legendary Creature — Elf Druid", []string{"Creature"}, []string{"Elf", "Druid"}},
+		{"Basic Snow Land — Island", []string{"Land"}, []string{"Island"}},
+		{"Artifact", []string{"Artifact"}, []string{}},
+		{"Instant // Sorcery", []string{"Instant"}, []string{}},
+		{"Enchantment Creature — Cat // Enchantment", []string{"Enchantment", "Creature"}, []string{"Cat"}},
+	}
+	for _, c := range cases {
+		types, subtypes := parseTypeLine(c.line)
+		if !reflect.DeepEqual(types, c.types) || !reflect.DeepEqual(subtypes, c.subtypes) {
+			t.Errorf("parseTypeLine(%q)=%v, %v; want %v, %v", c.line, types, subtypes, c.types, c.subtypes)
+		}
+	}
+}
+
+func TestLoadCardsDetect(t *testing.T) {
+	cases := []struct {
+		first json.Delim
+		key   string
+		want  string
+	}{
+		{'{', "Island", "mtgjson v4"},
+		{'{', "meta", "mtgjson v5"},
+		{'[', "", "scryfall"},
+	}
+	for _, c := range cases {
+		got := ""
+		for _, l := range Loaders {
+			if l.Detect(c.first, c.key) {
+				got = l.Name
+				break
+			}
+		}
+		if got != c.want {
+			t.Errorf("detected %c %q as %q; want %q", c.first, c.key, got, c.want)
+		}
+	}
+	if _, err := LoadCards([]byte(`"Island"`)); err != ErrUnknownFormat {
+		t.Errorf("LoadCards(string)=%v; want %v", err, ErrUnknownFormat)
+	}
+}
+
+func TestReadCardsCompressed(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/AtomicCards.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := LoadCards(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write(data)
+	zw.Close()
+	xz, err := ioutil.ReadFile("testdata/AtomicCards.json.xz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inputs := map[string][]byte{"gzip": gz.Bytes(), "xz": xz}
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			if _, err := exec.LookPath(xzCommand); name == "xz" && err != nil {
+				// TestReadCardsNoXZ checks the error without it.
+				t.Skipf("no %s command", xzCommand)
+			}
+			got, err := ReadCards(bytes.NewReader(input))
+			if err != nil {
+				t.Fatalf("ReadCards(%s): %v", name, err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ReadCards(%s)=%v; want %v", name, got, want)
+			}
+		})
+	}
+	if _, err := ReadCards(bytes.NewReader(gz.Bytes()[:20])); err == nil {
+		t.Errorf("ReadCards(truncated gzip) succeeded; want error")
+	}
+}
+
+func TestReadCardsNoXZ(t *testing.T) {
+	defer func(cmd string) { xzCommand = cmd }(xzCommand)
+	xzCommand = "no-such-xz-command"
+	_, err := ReadCardFile("testdata/AtomicCards.json.xz")
+	if err == nil || !strings.Contains(err.Error(), "needs the no-such-xz-command command") {
+		t.Errorf("ReadCardFile(.xz) without xz=%v; want an error naming the missing command", err)
+	}
+}
+
+// writeBigCardFile writes an mtgjson v5 file of n made-up cards, each with
+// fields that ReadCards throws away, and returns its path.
+func writeBigCardFile(b *testing.B, n int) string {
+	f, err := ioutil.TempFile("", "AtomicCards*.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	fmt.Fprint(w, `{"meta": {"version": "5.2.2"}, "data": {`)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			w.WriteString(",")
+		}
+		fmt.Fprintf(w, `"Card %d": [{"name": "Card %d", "type": "Creature — Bear", "text": "Trample", "manaValue": 2,
+			"legalities": {"legacy": "Legal", "vintage": "Legal", "commander": "Legal"},
+			"foreignData": [%s], "rulings": [%s], "purchaseUrls": {"tcgplayer": "https://example.com/%d"}}]`,
+			i, i, strings.Repeat(`{"language": "German", "name": "Karte", "text": "Trampelschaden"},`, 9)+`{}`,
+			strings.Repeat(`{"date": "2020-01-01", "text": "A long ruling about how this card works."},`, 9)+`{}`, i)
+	}
+	w.WriteString("}}")
+	if err := w.Flush(); err != nil {
+		b.Fatal(err)
+	}
+	return f.Name()
+}
+
+// benchmarkLoad reports the peak heap in use while loading a big card file.
+func benchmarkLoad(b *testing.B, load func(path string) ([]Card, error)) {
+	path := writeBigCardFile(b, 20000)
+	defer os.Remove(path)
+	b.ReportAllocs()
+	b.ResetTimer()
+	peak := uint64(0)
+	for i := 0; i < b.N; i++ {
+		done := make(chan bool)
+		go func() {
+			var m runtime.MemStats
+			for {
+				runtime.ReadMemStats(&m)
+				if m.HeapInuse > peak {
+					peak = m.HeapInuse
+				}
+				select {
+				case <-done:
+					return
+				case <-time.After(time.Millisecond):
+				}
+			}
+		}()
+		if _, err := load(path); err != nil {
+			b.Fatal(err)
+		}
+		done <- true
+	}
+	b.ReportMetric(float64(peak), "peak-heap-bytes")
+}
+
+func BenchmarkReadCards(b *testing.B) {
+	benchmarkLoad(b, func(path string) ([]Card, error) {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return ReadCards(f)
+	})
+}
+
+// BenchmarkReadFileUnmarshal is the old way of loading cards, for comparison.
+func BenchmarkReadFileUnmarshal(b *testing.B) {
+	benchmarkLoad(b, func(path string) ([]Card, error) {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var atomic struct {
+			Data map[string][]Card
+		}
+		if err := json.Unmarshal(data, &atomic); err != nil {
+			return nil, err
+		}
+		cards := []Card{}
+		for _, faces := range atomic.Data {
+			cards = append(cards, faces[0])
+		}
+		return cards, nil
+	})
+}
@@ -0,0 +1,191 @@
+// Package mtgcount counts the number of legal decks in various Magic the
+// Gathering formats, using card info from mtgjson.
+package mtgcount
+
+import (
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type Card struct {
+	Name       string
+	Type       string
+	Text       string // Oracle text.
+	Legalities map[string]string
+	// Colors of the mana symbols in the card's cost and text: W, U, B, R or G.
+	ColorIdentity []string
+	Printings     []string // Codes of the sets the card was printed in.
+	// OnlyReprints is set if Printings lacks the card's first printing, as
+	// in Scryfall oracle cards, which have one, often recent, printing of
+	// each card.
+	OnlyReprints bool
+	// Rarities the card was printed at, like common and mythic, if the card
+	// data has printings.
+	Rarities  []string
+	ManaValue float64
+	Colors    []string // Colors of the card itself, as in ColorIdentity.
+	// Types and subtypes from the type line, like Creature and Goblin.
+	// Supertypes like Legendary and Basic are not included.
+	Types, Subtypes []string
+}
+
+// Unlimited is the copy limit of cards a deck can have any number of.  It is
+// more than any deck can hold.
+const Unlimited = 1000
+
+var copiesRE = regexp.MustCompile(`A deck can have (any number of|up to (\w+)) cards named`)
+
+var numbers = map[string]int{
+	"one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6, "seven": 7,
+	"eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+}
+
+// CopyLimit returns the number of copies of c a deck can have where c is
+// legal: Unlimited for basic lands and cards whose text says "A deck can have
+// any number of cards named ...", N for cards whose text says "A deck can
+// have up to N cards named ...", and 4 otherwise.
+func CopyLimit(c Card) int {
+	if strings.HasPrefix(c.Type, "Basic Land") {
+		return Unlimited
+	}
+	m := copiesRE.FindStringSubmatch(c.Text)
+	switch {
+	case m == nil:
+		return 4
+	case m[2] == "":
+		return Unlimited
+	}
+	if n, ok := numbers[m[2]]; ok {
+		return n
+	}
+	if n, err := strconv.Atoi(m[2]); err == nil {
+		return n
+	}
+	return 4
+}
+
+// FormatLimits returns, for each format mentioned in the card data, the
+// maximum number of copies allowed in a deck of each card that is legal in
+// that format.  The card data may be in any format LoadCards accepts.
+func FormatLimits(mtgJSON []byte) (map[string][]int, error) {
+	cards, err := LoadCards(mtgJSON)
+	if err != nil {
+		return nil, err
+	}
+	return Limits(cards), nil
+}
+
+// Limits is like FormatLimits, but takes cards that have already been parsed.
+func Limits(cards []Card) map[string][]int {
+	limits := map[string][]int{}
+	for _, c := range cards {
+		for f, leg := range c.Legalities {
+			if _, ok := limits[f]; !ok {
+				limits[f] = []int{}
+			}
+			lim := 0
+			if leg == "Legal" {
+				lim = FormatLimit(f)(c)
+			} else if leg == "Restricted" {
+				lim = 1
+			}
+			if lim > 0 {
+				limits[f] = append(limits[f], lim)
+			}
+		}
+	}
+	return limits
+}
+
+// Cache key, used to speed up CountDecks.
+type deckKey struct {
+	main, side, numCards int
+}
+
+// CountDecks(M, S, L) returns the number of ways to make a deck with M
+// cards in the main deck and S cards in the sideboard where there are len(L)
+// cards to choose from, and there can be at most L[I] copies of card I in your
+// mainboard and sideboard combined (0 < I < len(L)).
+// Examples (mainboard/sideboard):
+//
+//	CountDecks(3, 0, []int{1,2,3})=6 (abb abc acc bbc bcc ccd)
+//	CountDecks(3, 3, []int{1,2,3})=6 (abb/ccc abc/bcc acc/bbc bbc/acc bcc/abc ccc/abb)
+//	CountDecks(3, 1, []int{1,2,3})=12 (abb/c abc/b abc/c acc/b acc/c bbc/a bbc/c bcc/a bcc/b bcc/c ccc/a ccc/b)
+//	CountDecks(4, 0, []int{1,2,3})=5 (abbc abcc accc bbcc bccc)
+//	CountDecks(4, 1, []int{1,2,3})=8 (abbc/c abcc/b abcc/b accc/b bbcc/a bbcc/c bccc/a bccc/b)
+//	CountDecks(4, 2, []int{1,2,3})=5 (abbc/cc abcc/bc accc/bb bbcc/ac bccc/ab)
+//	CountDecks(60, 15, []int{75})=1 (the "all islands" example)
+//
+// CountDecks multiplies out the generating function of the cards, grouped by
+// limit, which is much faster than recurring card by card as countDecks does.
+func CountDecks(numMain, numSide int, limit []int) *big.Int {
+	return countDecksGF(numMain, numSide, limit)
+}
+
+// countDecks computes CountDecks by recurring card by card.
+func countDecks(numMain, numSide int, limit []int) *big.Int {
+	return _countDecks(numMain, numSide, limit, map[deckKey]*big.Int{})
+}
+
+func _countDecks(numMain, numSide int, limit []int, cache map[deckKey]*big.Int) *big.Int {
+	if numMain+numSide == 0 {
+		return big.NewInt(1)
+	}
+	if len(limit) == 0 {
+		return big.NewInt(0)
+	}
+	key := deckKey{numMain, numSide, len(limit)}
+	if val, ok := cache[key]; ok {
+		return val
+	}
+	sum := big.NewInt(0)
+	for m := 0; m <= numMain && m <= limit[0]; m++ {
+		for s := 0; s <= numSide && m+s <= limit[0]; s++ {
+			sum.Add(sum, _countDecks(numMain-m, numSide-s, limit[1:], cache))
+		}
+	}
+	cache[key] = sum
+	return sum
+}
+
+// Cache key, used to speed up LimitedMultiChooose.
+type stockKey struct {
+	numToBuy, numProducts int
+}
+
+// LimitedMultiChoose(B, L) returns the number of ways to buy B items from a
+// store with len(L) products, where item I has only L[I] in stock (0 < I < N).
+// For example, LimitedMultiChoose(5, []int{3,4,6})=17, which is the number of
+// ways to choose 5 items to buy from a selection of 3 products, where product
+// 0 has 3 in stock, product 1 has 4 in stock, and product 2 has 6 in stock.
+// It is the number of decks with numToBuy cards and no sideboard.
+func LimitedMultiChoose(numToBuy int, numInStock []int) *big.Int {
+	return CountDecks(numToBuy, 0, numInStock)
+}
+
+// limitedMultiChoose computes LimitedMultiChoose by recurring product by
+// product.
+func limitedMultiChoose(numToBuy int, numInStock []int) *big.Int {
+	return _limitedMultiChoose(numToBuy, numInStock, map[stockKey]*big.Int{})
+}
+
+func _limitedMultiChoose(numToBuy int, numInStock []int, cache map[stockKey]*big.Int) *big.Int {
+	if numToBuy == 0 {
+		return big.NewInt(1)
+	}
+	if numToBuy < 0 || len(numInStock) == 0 {
+		return big.NewInt(0)
+	}
+	key := stockKey{numToBuy, len(numInStock)}
+	if val, ok := cache[key]; ok {
+		return val
+	}
+	sum := big.NewInt(0)
+	for i := 0; i <= numInStock[0]; i++ {
+		sum.Add(sum, _limitedMultiChoose(numToBuy-i, numInStock[1:], cache))
+	}
+	cache[key] = sum
+	return sum
+}
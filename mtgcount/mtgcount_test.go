@@ -0,0 +1,97 @@
+package mtgcount
+
+import (
+	"io/ioutil"
+	"math/big"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestCountDecks(t *testing.T) {
+	cases := []struct {
+		main, side int
+		limit      []int
+		want       int64
+	}{
+		{3, 0, []int{1, 2, 3}, 6},
+		{3, 3, []int{1, 2, 3}, 6},
+		{3, 1, []int{1, 2, 3}, 12},
+		{4, 0, []int{1, 2, 3}, 5},
+		{4, 1, []int{1, 2, 3}, 8},
+		{4, 2, []int{1, 2, 3}, 5},
+		{60, 15, []int{75}, 1},
+		{60, 15, []int{74}, 0},
+		{0, 0, []int{}, 1},
+	}
+	for _, c := range cases {
+		if got := CountDecks(c.main, c.side, c.limit); got.Cmp(big.NewInt(c.want)) != 0 {
+			t.Errorf("CountDecks(%d, %d, %v)=%v; want %d", c.main, c.side, c.limit, got, c.want)
+		}
+	}
+}
+
+func TestLimitedMultiChoose(t *testing.T) {
+	cases := []struct {
+		numToBuy   int
+		numInStock []int
+		want       int64
+	}{
+		{5, []int{3, 4, 6}, 17},
+		{0, []int{}, 1},
+		{1, []int{}, 0},
+		{2, []int{1, 1, 1}, 3},
+		{60, []int{1000}, 1},
+	}
+	for _, c := range cases {
+		if got := LimitedMultiChoose(c.numToBuy, c.numInStock); got.Cmp(big.NewInt(c.want)) != 0 {
+			t.Errorf("LimitedMultiChoose(%d, %v)=%v; want %d", c.numToBuy, c.numInStock, got, c.want)
+		}
+	}
+}
+
+func TestFormatLimits(t *testing.T) {
+	mtgJSON, err := ioutil.ReadFile("testdata/AllCards.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := FormatLimits(mtgJSON)
+	if err != nil {
+		t.Fatalf("FormatLimits(testdata/AllCards.json): %v", err)
+	}
+	for _, l := range got {
+		sort.Ints(l)
+	}
+	want := map[string][]int{
+		"standard":  {4, 1000},
+		"pioneer":   {4, 7, 1000, 1000},
+		"modern":    {4, 4, 7, 9, 1000, 1000, 1000},
+		"legacy":    {4, 4, 4, 7, 9, 1000, 1000, 1000},
+		"vintage":   {1, 1, 4, 4, 4, 7, 9, 1000, 1000, 1000},
+		"pauper":    {4, 4, 4, 1000, 1000},
+		"commander": {1, 1, 1, 7, 9, 1000, 1000, 1000},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FormatLimits(testdata/AllCards.json)=%v; want %v", got, want)
+	}
+}
+
+func TestCopyLimit(t *testing.T) {
+	cases := []struct {
+		card Card
+		want int
+	}{
+		{Card{Name: "Lightning Bolt", Type: "Instant", Text: "Lightning Bolt deals 3 damage to any target."}, 4},
+		{Card{Name: "Island", Type: "Basic Land — Island"}, Unlimited},
+		{Card{Name: "Rat Colony", Type: "Creature — Rat", Text: "Rat Colony gets +1/+0 for each other Rat you control.\nA deck can have any number of cards named Rat Colony."}, Unlimited},
+		{Card{Name: "Dragon's Approach", Type: "Instant", Text: "Dragon's Approach deals 3 damage to each opponent. ...\nA deck can have any number of cards named Dragon's Approach."}, Unlimited},
+		{Card{Name: "Seven Dwarves", Type: "Creature — Dwarf", Text: "A deck can have up to seven cards named Seven Dwarves."}, 7},
+		{Card{Name: "Nazgûl", Type: "Creature — Wraith Knight", Text: "Deathtouch\nA deck can have up to nine cards named Nazgûl."}, 9},
+		{Card{Name: "Hypothetical", Type: "Creature", Text: "A deck can have up to 15 cards named Hypothetical."}, 15},
+	}
+	for _, c := range cases {
+		if got := CopyLimit(c.card); got != c.want {
+			t.Errorf("CopyLimit(%s)=%d; want %d", c.card.Name, got, c.want)
+		}
+	}
+}
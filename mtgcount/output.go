@@ -0,0 +1,121 @@
+package mtgcount
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Count is the number of decks in one format, color identity, etc.
+type Count struct {
+	Name  string
+	Decks *big.Int
+}
+
+// Outputs lists the output formats WriteCounts accepts.
+var Outputs = []string{"text", "json", "csv"}
+
+// WriteCounts writes counts to w in the given output format: "text" (one
+// "name: approximation (exact)" line per count), "json" (an array of
+// {column: name, "decks": decks} objects, with decks as a string of digits so
+// that no precision is lost) or "csv" (a "column,decks" header and one row
+// per count).
+func WriteCounts(w io.Writer, output, column string, counts []Count) error {
+	switch output {
+	case "text":
+		for _, c := range counts {
+			if _, err := fmt.Fprintf(w, "%8s: %.3g (%v)\n", c.Name, new(big.Float).SetInt(c.Decks), c.Decks); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "json":
+		rows := []map[string]string{}
+		for _, c := range counts {
+			rows = append(rows, map[string]string{column: c.Name, "decks": c.Decks.String()})
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{column, "decks"})
+		for _, c := range counts {
+			cw.Write([]string{c.Name, c.Decks.String()})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("mtgcount: unknown output format %q (want one of %s)", output, strings.Join(Outputs, ", "))
+}
+
+// Approx is an approximate count: log10 of the number of decks, within Err.
+type Approx struct {
+	Name       string
+	Log10, Err float64
+}
+
+// WriteApprox is WriteCounts for approximate counts: "text" writes
+// "name: 10^log10 (±err)" lines, "json" {column: name, "log10": log10,
+// "error": err} objects and "csv" "column,log10,error" rows.
+func WriteApprox(w io.Writer, output, column string, counts []Approx) error {
+	switch output {
+	case "text":
+		for _, c := range counts {
+			if _, err := fmt.Fprintf(w, "%8s: 10^%.6f (±%.1e)\n", c.Name, c.Log10, c.Err); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "json":
+		rows := []map[string]interface{}{}
+		for _, c := range counts {
+			row := map[string]interface{}{column: c.Name, "log10": c.Log10, "error": c.Err}
+			if math.IsInf(c.Log10, -1) {
+				// JSON has no -Inf; there are no decks.
+				row["log10"] = nil
+			}
+			rows = append(rows, row)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{column, "log10", "error"})
+		for _, c := range counts {
+			cw.Write([]string{c.Name, strconv.FormatFloat(c.Log10, 'f', -1, 64), strconv.FormatFloat(c.Err, 'g', -1, 64)})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("mtgcount: unknown output format %q (want one of %s)", output, strings.Join(Outputs, ", "))
+}
+
+// SelectFormats parses a comma-separated list of formats, checking that each
+// is a key of limits.  The list "all" selects every format, sorted by name.
+func SelectFormats(limits map[string][]int, list string) ([]string, error) {
+	known := []string{}
+	for f := range limits {
+		known = append(known, f)
+	}
+	sort.Strings(known)
+	if list == "all" {
+		return known, nil
+	}
+	formats := []string{}
+	for _, f := range strings.Split(list, ",") {
+		f = strings.TrimSpace(f)
+		if _, ok := limits[f]; !ok {
+			return nil, fmt.Errorf("mtgcount: unknown format %q (have %s)", f, strings.Join(known, ", "))
+		}
+		formats = append(formats, f)
+	}
+	return formats, nil
+}
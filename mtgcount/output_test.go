@@ -0,0 +1,78 @@
+package mtgcount
+
+import (
+	"bytes"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestWriteCounts(t *testing.T) {
+	googol := new(big.Int).Exp(big.NewInt(10), big.NewInt(100), nil)
+	counts := []Count{{"standard", big.NewInt(15)}, {"vintage", googol}}
+	cases := []struct {
+		output string
+		want   string
+	}{
+		{"text", "standard: 15 (15)\n vintage: 1e+100 (1" + zeros(100) + ")\n"},
+		{"json", "[\n  {\n    \"decks\": \"15\",\n    \"format\": \"standard\"\n  },\n  {\n    \"decks\": \"1" + zeros(100) + "\",\n    \"format\": \"vintage\"\n  }\n]\n"},
+		{"csv", "format,decks\nstandard,15\nvintage,1" + zeros(100) + "\n"},
+	}
+	for _, c := range cases {
+		var buf bytes.Buffer
+		if err := WriteCounts(&buf, c.output, "format", counts); err != nil {
+			t.Errorf("WriteCounts(%q): %v", c.output, err)
+		}
+		if got := buf.String(); got != c.want {
+			t.Errorf("WriteCounts(%q)=%q; want %q", c.output, got, c.want)
+		}
+	}
+	if err := WriteCounts(&bytes.Buffer{}, "xml", "format", counts); err == nil {
+		t.Errorf("WriteCounts(\"xml\") succeeded; want error")
+	}
+}
+
+func TestWriteApprox(t *testing.T) {
+	counts := []Approx{{"standard", 152.5, 1e-10}, {"none", math.Inf(-1), 0}}
+	cases := []struct {
+		output string
+		want   string
+	}{
+		{"text", "standard: 10^152.500000 (±1.0e-10)\n    none: 10^-Inf (±0.0e+00)\n"},
+		{"json", "[\n  {\n    \"error\": 1e-10,\n    \"format\": \"standard\",\n    \"log10\": 152.5\n  },\n  {\n    \"error\": 0,\n    \"format\": \"none\",\n    \"log10\": null\n  }\n]\n"},
+		{"csv", "format,log10,error\nstandard,152.5,1e-10\nnone,-Inf,0\n"},
+	}
+	for _, c := range cases {
+		var buf bytes.Buffer
+		if err := WriteApprox(&buf, c.output, "format", counts); err != nil {
+			t.Errorf("WriteApprox(%q): %v", c.output, err)
+		}
+		if got := buf.String(); got != c.want {
+			t.Errorf("WriteApprox(%q)=%q; want %q", c.output, got, c.want)
+		}
+	}
+}
+
+func zeros(n int) string {
+	return string(bytes.Repeat([]byte{'0'}, n))
+}
+
+func TestSelectFormats(t *testing.T) {
+	limits := map[string][]int{"modern": {4}, "pioneer": {4}, "commander": {1}}
+	cases := []struct {
+		list    string
+		want    []string
+		wantErr bool
+	}{
+		{"pioneer,modern", []string{"pioneer", "modern"}, false},
+		{"all", []string{"commander", "modern", "pioneer"}, false},
+		{"modern, historic", nil, true},
+	}
+	for _, c := range cases {
+		got, err := SelectFormats(limits, c.list)
+		if (err != nil) != c.wantErr || !reflect.DeepEqual(got, c.want) {
+			t.Errorf("SelectFormats(%q)=%v, %v; want %v, error %v", c.list, got, err, c.want, c.wantErr)
+		}
+	}
+}
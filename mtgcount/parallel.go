@@ -0,0 +1,214 @@
+package mtgcount
+
+import (
+	"container/list"
+	"context"
+	"math/big"
+	"sort"
+	"sync"
+)
+
+// CountDecksContext is like CountDecks, but stops early if ctx is done,
+// returning its error.
+func CountDecksContext(ctx context.Context, numMain, numSide int, limit []int) (*big.Int, error) {
+	return new(Counter).Count(ctx, numMain, numSide, limit)
+}
+
+// Counter computes CountDecks, remembering the generating functions of
+// groups of cards and the counts it has computed, so that counting formats
+// whose limits coincide, in whole or in part, shares the work.  It is safe
+// for concurrent use; the zero Counter is ready to use.
+type Counter struct {
+	// MaxEntries, if positive, is the most values the Counter remembers;
+	// it forgets the least recently used first.
+	MaxEntries int
+	mu         sync.Mutex
+	entries    map[interface{}]*entry
+	lru        *list.List // Of keys, most recently used first.
+}
+
+type entry struct {
+	done chan struct{}
+	val  interface{}
+	err  error
+	elem *list.Element // In lru.
+}
+
+type (
+	countKey struct {
+		main, side int
+		limits     string // As from limitsKey.
+	}
+	polyKey    struct{ lim, n, main, side int }
+	samplerKey struct {
+		key        string
+		main, side int
+	}
+)
+
+// memo returns the value for key, calling compute unless another call has
+// or is computing it.  Failed computations aren't remembered, and since
+// computations only fail when their caller's context is done, a call whose
+// own ctx isn't done computes the value itself when another call's
+// computation fails.
+func (c *Counter) memo(ctx context.Context, key interface{}, compute func() (interface{}, error)) (interface{}, error) {
+	for {
+		c.mu.Lock()
+		if c.entries == nil {
+			c.entries = map[interface{}]*entry{}
+			c.lru = list.New()
+		}
+		e, ok := c.entries[key]
+		if !ok {
+			e = &entry{done: make(chan struct{}), elem: c.lru.PushFront(key)}
+			c.entries[key] = e
+			if c.MaxEntries > 0 && c.lru.Len() > c.MaxEntries {
+				// Callers waiting on the forgotten entry still get its value.
+				delete(c.entries, c.lru.Remove(c.lru.Back()))
+			}
+			c.mu.Unlock()
+			e.val, e.err = compute()
+			if e.err != nil {
+				c.mu.Lock()
+				if c.entries[key] == e {
+					delete(c.entries, key)
+					c.lru.Remove(e.elem)
+				}
+				c.mu.Unlock()
+			}
+			close(e.done)
+			return e.val, e.err
+		}
+		c.lru.MoveToFront(e.elem)
+		c.mu.Unlock()
+		select {
+		case <-e.done:
+			if e.err == nil || ctx.Err() != nil {
+				return e.val, e.err
+			}
+			// Another caller's context was done; try again.
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Count returns CountDecks(numMain, numSide, limit), or ctx's error if ctx is
+// done first.
+func (c *Counter) Count(ctx context.Context, numMain, numSide int, limit []int) (*big.Int, error) {
+	if numMain < 0 || numSide < 0 {
+		return big.NewInt(0), nil
+	}
+	sizes := map[int]int{}
+	capped := []int{}
+	for _, lim := range limit {
+		if lim > numMain+numSide {
+			lim = numMain + numSide
+		}
+		if lim > 0 {
+			sizes[lim]++
+			capped = append(capped, lim)
+		}
+	}
+	n, err := c.memo(ctx, countKey{numMain, numSide, limitsKey(capped)}, func() (interface{}, error) {
+		lims := []int{}
+		for lim := range sizes {
+			lims = append(lims, lim)
+		}
+		sort.Ints(lims)
+		p := one(numMain, numSide)
+		for _, lim := range lims {
+			g, err := c.memo(ctx, polyKey{lim, sizes[lim], numMain, numSide}, func() (interface{}, error) {
+				g := &group{lim: lim, cards: make([]int, sizes[lim])}
+				if err := g.expandContext(ctx, numMain, numSide); err != nil {
+					return nil, err
+				}
+				return g.poly, nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			p = p.mul(g.(poly))
+		}
+		return p[numMain][numSide], nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return n.(*big.Int), nil
+}
+
+// Sampler returns NewSamplerContext(ctx, numMain, numSide, names, limit),
+// remembering it like a count.  key must identify names and limit, as a
+// format name does; calls with the same key and deck size share a sampler.
+func (c *Counter) Sampler(ctx context.Context, key string, numMain, numSide int, names []string, limit []int) (*Sampler, error) {
+	type result struct {
+		sm  *Sampler
+		err error
+	}
+	r, err := c.memo(ctx, samplerKey{key, numMain, numSide}, func() (interface{}, error) {
+		sm, err := NewSamplerContext(ctx, numMain, numSide, names, limit)
+		if err != nil && err != ErrNoDecks {
+			return nil, err
+		}
+		// Remember that there are no decks, too.
+		return result{sm, err}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.(result).sm, r.(result).err
+}
+
+// CountFormats calls count for each format, running up to workers calls at
+// once, and returns the counts in the order of formats.  If a call fails,
+// CountFormats cancels the context of the others and returns the error.
+func CountFormats(ctx context.Context, formats []string, workers int, count func(ctx context.Context, format string) (*big.Int, error)) ([]Count, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if workers < 1 {
+		workers = 1
+	}
+	counts := make([]Count, len(formats))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				n, err := count(ctx, formats[i])
+				if err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+				counts[i] = Count{formats[i], n}
+			}
+		}()
+	}
+feed:
+	for i := range formats {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
@@ -0,0 +1,156 @@
+package mtgcount
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestCounter(t *testing.T) {
+	cards := loadTestCards(t, "testdata/AtomicCards.json")
+	limits := Limits(cards)
+	formats := []string{"standard", "pioneer", "modern", "legacy", "vintage", "pauper", "commander"}
+	var c Counter
+	got, err := CountFormats(context.Background(), formats, 3, func(ctx context.Context, f string) (*big.Int, error) {
+		return c.Count(ctx, 60, 15, limits[f])
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Count{}
+	for _, f := range formats {
+		want = append(want, Count{f, CountDecks(60, 15, limits[f])})
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CountFormats(Counter.Count)=%v; want %v", got, want)
+	}
+	// Each format has its own count, but formats share the groups of cards
+	// they have in common, like the 4-ofs of some size.
+	polys := 0
+	for k := range c.entries {
+		if _, ok := k.(polyKey); ok {
+			polys++
+		}
+	}
+	if counts := len(c.entries) - polys; counts != 7 || polys >= 7*3 {
+		t.Errorf("Counter remembered %d counts and %d groups; want 7 counts, sharing groups", counts, polys)
+	}
+}
+
+func TestCounterMaxEntries(t *testing.T) {
+	c := Counter{MaxEntries: 3}
+	for main := 0; main < 20; main++ {
+		for _, limit := range [][]int{{1, 2, 3}, {4, 4, 4, 4, Unlimited}} {
+			got, err := c.Count(context.Background(), main, 2, limit)
+			if want := CountDecks(main, 2, limit); err != nil || got.Cmp(want) != 0 {
+				t.Errorf("Count(%d, 2, %v)=%v, %v; want %v", main, limit, got, err, want)
+			}
+			if len(c.entries) > 3 || c.lru.Len() != len(c.entries) {
+				t.Fatalf("Counter{MaxEntries: 3} has %d entries, %d in lru", len(c.entries), c.lru.Len())
+			}
+		}
+	}
+}
+
+func TestCountDecksContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := CountDecksContext(ctx, 60, 15, benchLimits()); err != context.Canceled {
+		t.Errorf("CountDecksContext(cancelled)=%v; want %v", err, context.Canceled)
+	}
+	if got, err := CountDecksContext(context.Background(), 3, 1, []int{1, 2, 3}); err != nil || got.Int64() != 12 {
+		t.Errorf("CountDecksContext(3, 1, [1 2 3])=%v, %v; want 12", got, err)
+	}
+}
+
+func TestCounterSampler(t *testing.T) {
+	var c Counter
+	names, limit := []string{"a", "b", "c"}, []int{1, 2, 3}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.Sampler(ctx, "f", 3, 1, names, limit); err != context.Canceled {
+		t.Errorf("Sampler(cancelled)=%v; want %v", err, context.Canceled)
+	}
+	sm, err := c.Sampler(context.Background(), "f", 3, 1, names, limit)
+	if err != nil || sm.Count().Int64() != 12 {
+		t.Fatalf("Sampler(3, 1, %v)=%v, %v; want 12 decks", limit, sm, err)
+	}
+	if again, _ := c.Sampler(context.Background(), "f", 3, 1, names, limit); again != sm {
+		t.Errorf("Sampler with the same key made a new sampler")
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := c.Sampler(context.Background(), "f", 7, 0, names, limit); err != ErrNoDecks {
+			t.Errorf("Sampler(7, 0, %v)=%v; want %v", limit, err, ErrNoDecks)
+		}
+	}
+}
+
+func TestCounterCancelOne(t *testing.T) {
+	var c Counter
+	// A's computation fails when A's context is cancelled, while B waits on
+	// it with a live context; B then computes the value itself.
+	ctxA, cancelA := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	errA := make(chan error)
+	go func() {
+		_, err := c.memo(ctxA, "key", func() (interface{}, error) {
+			close(started)
+			<-ctxA.Done()
+			return nil, ctxA.Err()
+		})
+		errA <- err
+	}()
+	<-started
+	valB := make(chan interface{})
+	go func() {
+		v, err := c.memo(context.Background(), "key", func() (interface{}, error) { return 42, nil })
+		if err != nil {
+			t.Errorf("memo(B)=%v; want 42", err)
+		}
+		valB <- v
+	}()
+	cancelA()
+	if err := <-errA; err != context.Canceled {
+		t.Errorf("memo(A)=%v; want %v", err, context.Canceled)
+	}
+	if v := <-valB; v != 42 {
+		t.Errorf("memo(B)=%v; want 42", v)
+	}
+
+	// The same through Count, cancelling one of two counts of the same
+	// limits part way.
+	limit := benchLimits()
+	want := CountDecks(60, 15, limit)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := c.Count(ctx, 60, 15, limit)
+		done <- err
+	}()
+	go func() {
+		got, err := c.Count(context.Background(), 60, 15, limit)
+		if err != nil || got.Cmp(want) != 0 {
+			t.Errorf("Count(live context)=%v, %v; want %v", got, err, want)
+		}
+		done <- nil
+	}()
+	cancel()
+	<-done
+	<-done
+}
+
+func TestCountFormatsError(t *testing.T) {
+	bad := errors.New("bad format")
+	_, err := CountFormats(context.Background(), []string{"a", "b", "c", "d"}, 2, func(ctx context.Context, f string) (*big.Int, error) {
+		if f == "b" {
+			return nil, bad
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if err != bad {
+		t.Errorf("CountFormats(failing)=%v; want %v", err, bad)
+	}
+}
@@ -0,0 +1,211 @@
+package mtgcount
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+var ErrBadRank = errors.New("mtgcount: rank out of range")
+
+/*
+Rank and Unrank number the decks of a Sampler from 0 to Count()-1.  The
+numbering depends only on the names and limits given to NewSampler, so a rank
+is a compact, canonical ID for a deck.  Decks are ordered by, for each group
+of cards that share a limit in increasing order of limit:
+
+	how many of the group's cards go in the main deck and sideboard
+	how many distinct cards of the group are used
+	which cards are used
+	how many copies of each go in the main deck and sideboard
+*/
+
+// Rank returns the number of the deck with the given main deck and
+// sideboard, listed as by Sample, one name per copy in any order.
+func (sm *Sampler) Rank(main, side []string) (*big.Int, error) {
+	if len(main) != sm.numMain || len(side) != sm.numSide {
+		return nil, fmt.Errorf("mtgcount: deck has %d/%d cards; want %d/%d", len(main), len(side), sm.numMain, sm.numSide)
+	}
+	copies := map[int][2]int{}
+	for i, names := range [][]string{main, side} {
+		for _, name := range names {
+			c, ok := sm.index[name]
+			if !ok {
+				return nil, fmt.Errorf("mtgcount: unknown card %q", name)
+			}
+			n := copies[c]
+			n[i]++
+			copies[c] = n
+		}
+	}
+	for c, n := range copies {
+		if n[0]+n[1] > sm.limit[c] {
+			return nil, fmt.Errorf("mtgcount: %d copies of %s; limit %d", n[0]+n[1], sm.names[c], sm.limit[c])
+		}
+	}
+	// The rank is a mixed-radix number with one digit per group: the
+	// offset of the group's main and sideboard counts, and the group's own
+	// rank, with the rest of the groups below it.
+	type digit struct {
+		offset, rank, rest *big.Int
+	}
+	digits := []digit{}
+	m, s := sm.numMain, sm.numSide
+	for i, g := range sm.groups {
+		used := []int{} // Positions in g.cards.
+		gm, gs := 0, 0
+		for j, c := range g.cards {
+			if n, ok := copies[c]; ok {
+				used = append(used, j)
+				gm, gs = gm+n[0], gs+n[1]
+			}
+		}
+		offset := weightsBefore(gm, gs, s, func(a, b int) *big.Int {
+			return new(big.Int).Mul(g.poly[a][b], sm.suffix[i+1][m-a][s-b])
+		})
+		// Rank within the group.
+		n, k := len(g.cards), len(used)
+		rank := new(big.Int)
+		for j := 0; j < k; j++ {
+			rank.Add(rank, new(big.Int).Mul(new(big.Int).Binomial(int64(n), int64(j)), g.powers[j][gm][gs]))
+		}
+		rank.Add(rank, new(big.Int).Mul(combinationRank(used), g.powers[k][gm][gs]))
+		am, as := gm, gs
+		for j, p := range used {
+			n := copies[g.cards[p]]
+			rest := g.powers[k-1-j]
+			rank.Add(rank, weightsBefore(n[0], n[1], as, func(a, b int) *big.Int {
+				if a+b == 0 || a+b > g.lim {
+					return new(big.Int)
+				}
+				return rest[am-a][as-b]
+			}))
+			am, as = am-n[0], as-n[1]
+		}
+		m, s = m-gm, s-gs
+		digits = append(digits, digit{offset, rank, sm.suffix[i+1][m][s]})
+	}
+	if m != 0 || s != 0 {
+		// Only cards with a limit of 0 can account for the rest.
+		return nil, fmt.Errorf("mtgcount: deck has cards with a limit of 0")
+	}
+	x := new(big.Int)
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := digits[i]
+		x.Add(x, new(big.Int).Mul(d.rank, d.rest))
+		x.Add(x, d.offset)
+	}
+	return x, nil
+}
+
+// Unrank returns the deck with the given number, as Sample does.  If x is
+// not in [0, Count()), it returns ErrBadRank.
+func (sm *Sampler) Unrank(x *big.Int) (main, side []string, err error) {
+	if x.Sign() < 0 || x.Cmp(sm.Count()) >= 0 {
+		return nil, nil, ErrBadRank
+	}
+	x = new(big.Int).Set(x)
+	type copies struct{ card, main, side int }
+	deck := []copies{}
+	m, s := sm.numMain, sm.numSide
+	for i, g := range sm.groups {
+		gm, gs := digit(x, m, s, func(a, b int) *big.Int {
+			return new(big.Int).Mul(g.poly[a][b], sm.suffix[i+1][m-a][s-b])
+		})
+		m, s = m-gm, s-gs
+		// x is now the group's rank times the number of ways to fill the
+		// rest of the deck, plus the rank of the rest.
+		y := new(big.Int)
+		y.QuoRem(x, sm.suffix[i+1][m][s], x)
+		n := len(g.cards)
+		k := 0
+		for c := new(big.Int); ; k++ {
+			c.Binomial(int64(n), int64(k))
+			c.Mul(c, g.powers[k][gm][gs])
+			if y.Cmp(c) < 0 {
+				break
+			}
+			y.Sub(y, c)
+		}
+		subset := new(big.Int)
+		subset.QuoRem(y, g.powers[k][gm][gs], y)
+		for j, p := range combinationUnrank(subset, n, k) {
+			rest := g.powers[k-1-j]
+			a, b := digit(y, gm, gs, func(a, b int) *big.Int {
+				if a+b == 0 || a+b > g.lim {
+					return new(big.Int)
+				}
+				return rest[gm-a][gs-b]
+			})
+			deck = append(deck, copies{g.cards[p], a, b})
+			gm, gs = gm-a, gs-b
+		}
+	}
+	sort.Slice(deck, func(i, j int) bool { return deck[i].card < deck[j].card })
+	for _, c := range deck {
+		for i := 0; i < c.main; i++ {
+			main = append(main, sm.names[c.card])
+		}
+		for i := 0; i < c.side; i++ {
+			side = append(side, sm.names[c.card])
+		}
+	}
+	return main, side, nil
+}
+
+// digit returns the first (a, b), with 0 ≤ a ≤ m and 0 ≤ b ≤ s, such that x
+// is less than the sum of the weights up to and including (a, b), and
+// subtracts the weights before (a, b) from x.
+func digit(x *big.Int, m, s int, weight func(a, b int) *big.Int) (int, int) {
+	for a := 0; a <= m; a++ {
+		for b := 0; b <= s; b++ {
+			w := weight(a, b)
+			if x.Cmp(w) < 0 {
+				return a, b
+			}
+			x.Sub(x, w)
+		}
+	}
+	panic("mtgcount: rank exceeds total weight")
+}
+
+// weightsBefore is the inverse of digit: it returns the sum of the weights
+// before (a0, b0), for b up to s.
+func weightsBefore(a0, b0, s int, weight func(a, b int) *big.Int) *big.Int {
+	sum := new(big.Int)
+	for a := 0; a <= a0; a++ {
+		for b := 0; b <= s && (a < a0 || b < b0); b++ {
+			sum.Add(sum, weight(a, b))
+		}
+	}
+	return sum
+}
+
+// combinationRank returns the rank of the increasing positions p among the
+// combinations of len(p) positions, in the combinatorial number system:
+// Σ C(p[j], j+1).
+func combinationRank(p []int) *big.Int {
+	x := new(big.Int)
+	for j, pj := range p {
+		x.Add(x, new(big.Int).Binomial(int64(pj), int64(j+1)))
+	}
+	return x
+}
+
+// combinationUnrank returns the increasing positions in [0, n) of the
+// combination of k positions with rank x.
+func combinationUnrank(x *big.Int, n, k int) []int {
+	x = new(big.Int).Set(x)
+	p := make([]int, k)
+	c := new(big.Int)
+	for j := k - 1; j >= 0; j-- {
+		// The largest position whose binomial is at most x.
+		p[j] = sort.Search(n, func(q int) bool {
+			return c.Binomial(int64(q), int64(j+1)).Cmp(x) > 0
+		}) - 1
+		x.Sub(x, c.Binomial(int64(p[j]), int64(j+1)))
+		n = p[j]
+	}
+	return p
+}
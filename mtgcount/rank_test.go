@@ -0,0 +1,99 @@
+package mtgcount
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestRankRoundTrip(t *testing.T) {
+	cases := []struct {
+		main, side int
+		limit      []int
+	}{
+		{3, 0, []int{1, 2, 3}},
+		{3, 1, []int{1, 2, 3}},
+		{4, 2, []int{1, 2, 3}},
+		{5, 2, []int{0, 4, 1, 4, Unlimited, 2, 1}},
+		{6, 0, []int{4, 4, 4}},
+	}
+	for _, c := range cases {
+		names := make([]string, len(c.limit))
+		for i := range names {
+			names[i] = string(rune('a' + i))
+		}
+		sm, err := NewSampler(c.main, c.side, names, c.limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := countDecks(c.main, c.side, c.limit); sm.Count().Cmp(want) != 0 {
+			t.Errorf("NewSampler(%d, %d, %v).Count()=%v; want %v", c.main, c.side, c.limit, sm.Count(), want)
+		}
+		seen := map[string]bool{}
+		for x := int64(0); x < sm.Count().Int64(); x++ {
+			main, side, err := sm.Unrank(big.NewInt(x))
+			if err != nil {
+				t.Fatalf("Unrank(%d): %v", x, err)
+			}
+			key := fmtDeck(main, side)
+			if seen[key] {
+				t.Errorf("%v: Unrank(%d)=%s again", c.limit, x, key)
+			}
+			seen[key] = true
+			if got, err := sm.Rank(main, side); err != nil || got.Int64() != x {
+				t.Errorf("%v: Rank(%s)=%v, %v; want %d", c.limit, key, got, err, x)
+			}
+		}
+	}
+}
+
+func TestRankLarge(t *testing.T) {
+	cards := loadTestCards(t, "testdata/AtomicCards.json")
+	names, limit := FormatCards(cards, "vintage")
+	sm, err := NewSampler(60, 15, names, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		main, side := sm.Sample(r)
+		x, err := sm.Rank(main, side)
+		if err != nil {
+			t.Fatalf("Rank(%s): %v", fmtDeck(main, side), err)
+		}
+		main2, side2, err := sm.Unrank(x)
+		if err != nil || !reflect.DeepEqual(main, main2) || !reflect.DeepEqual(side, side2) {
+			t.Errorf("Unrank(Rank(%s))=%s, %v", fmtDeck(main, side), fmtDeck(main2, side2), err)
+		}
+	}
+}
+
+func TestRankErrors(t *testing.T) {
+	sm, err := NewSampler(2, 1, []string{"a", "b", "c"}, []int{1, 2, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad := [][2][]string{
+		{{"a", "b"}, {}},    // Too few cards.
+		{{"a", "a"}, {"b"}}, // Too many copies.
+		{{"b", "b"}, {"b"}}, // Too many copies across main and side.
+		{{"a", "d"}, {"b"}}, // Unknown card.
+		{{"a", "c"}, {"b"}}, // Limit of 0.
+	}
+	for _, d := range bad {
+		if x, err := sm.Rank(d[0], d[1]); err == nil {
+			t.Errorf("Rank(%s)=%v; want error", fmtDeck(d[0], d[1]), x)
+		}
+	}
+	for _, x := range []int64{-1, sm.Count().Int64()} {
+		if _, _, err := sm.Unrank(big.NewInt(x)); err != ErrBadRank {
+			t.Errorf("Unrank(%d) error=%v; want %v", x, err, ErrBadRank)
+		}
+	}
+}
+
+func fmtDeck(main, side []string) string {
+	return fmt.Sprint(main, "/", side)
+}
@@ -0,0 +1,162 @@
+package mtgcount
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// NotLegalStatus is the legality of a card that a format doesn't mention.
+const NotLegalStatus = "Not Legal"
+
+// FormatStats summarizes the cards of a format.  Unlimited counts the legal
+// cards a deck can have any number of, and Types the legal and restricted
+// cards of each type, a card with several types counting for each.
+type FormatStats struct {
+	Format     string         `json:"format"`
+	Legal      int            `json:"legal"`
+	Restricted int            `json:"restricted"`
+	Banned     int            `json:"banned"`
+	Unlimited  int            `json:"unlimited"`
+	Types      map[string]int `json:"types"`
+}
+
+// LegalityDiff is a card whose legality differs between two formats.
+type LegalityDiff struct {
+	Card string `json:"card"`
+	A    string `json:"a"`
+	B    string `json:"b"`
+}
+
+// FormatDiff lists the cards whose legality differs between formats A and B.
+type FormatDiff struct {
+	A     string         `json:"a"`
+	B     string         `json:"b"`
+	Cards []LegalityDiff `json:"cards"`
+}
+
+// Report compares formats, for snapshots of card data to be diffed.
+type Report struct {
+	Formats []FormatStats `json:"formats"`
+	Diffs   []FormatDiff  `json:"diffs"`
+}
+
+// Stats returns the stats of each of the given formats.
+func Stats(cards []Card, formats []string) []FormatStats {
+	stats := []FormatStats{}
+	for _, f := range formats {
+		s := FormatStats{Format: f, Types: map[string]int{}}
+		for _, c := range cards {
+			switch c.Legalities[f] {
+			case "Legal":
+				s.Legal++
+				if CopyLimit(c) == Unlimited {
+					s.Unlimited++
+				}
+			case "Restricted":
+				s.Restricted++
+			case "Banned":
+				s.Banned++
+				continue
+			default:
+				continue
+			}
+			for _, t := range c.Types {
+				s.Types[t]++
+			}
+		}
+		stats = append(stats, s)
+	}
+	return stats
+}
+
+// DiffFormats returns the cards whose legality differs between formats a
+// and b, by name.
+func DiffFormats(cards []Card, a, b string) FormatDiff {
+	d := FormatDiff{A: a, B: b, Cards: []LegalityDiff{}}
+	legality := func(c Card, f string) string {
+		if leg, ok := c.Legalities[f]; ok {
+			return leg
+		}
+		return NotLegalStatus
+	}
+	for _, c := range cards {
+		if la, lb := legality(c, a), legality(c, b); la != lb {
+			d.Cards = append(d.Cards, LegalityDiff{c.Name, la, lb})
+		}
+	}
+	return d
+}
+
+// ReportOutputs lists the output formats WriteReport accepts.
+var ReportOutputs = []string{"markdown", "json"}
+
+// WriteReport writes r to w as "markdown" tables or "json".
+func WriteReport(w io.Writer, output string, r Report) error {
+	switch output {
+	case "markdown":
+		return writeMarkdown(w, r)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	}
+	return fmt.Errorf("mtgcount: unknown output format %q (want one of %s)", output, strings.Join(ReportOutputs, ", "))
+}
+
+func writeMarkdown(w io.Writer, r Report) error {
+	var b strings.Builder
+	row := func(cells ...interface{}) {
+		for _, c := range cells {
+			fmt.Fprintf(&b, "| %v ", c)
+		}
+		b.WriteString("|\n")
+	}
+	b.WriteString("## Formats\n\n")
+	row("format", "legal", "restricted", "banned", "unlimited")
+	row("---", "---:", "---:", "---:", "---:")
+	types := map[string]bool{}
+	for _, s := range r.Formats {
+		row(s.Format, s.Legal, s.Restricted, s.Banned, s.Unlimited)
+		for t := range s.Types {
+			types[t] = true
+		}
+	}
+	if len(types) > 0 {
+		b.WriteString("\n## Card types\n\n")
+		header, align := []interface{}{"type"}, []interface{}{"---"}
+		for _, s := range r.Formats {
+			header, align = append(header, s.Format), append(align, "---:")
+		}
+		row(header...)
+		row(align...)
+		sorted := []string{}
+		for t := range types {
+			sorted = append(sorted, t)
+		}
+		sort.Strings(sorted)
+		for _, t := range sorted {
+			cells := []interface{}{t}
+			for _, s := range r.Formats {
+				cells = append(cells, s.Types[t])
+			}
+			row(cells...)
+		}
+	}
+	for _, d := range r.Diffs {
+		fmt.Fprintf(&b, "\n## %s vs. %s\n\n", d.A, d.B)
+		if len(d.Cards) == 0 {
+			b.WriteString("No differences.\n")
+			continue
+		}
+		row("card", d.A, d.B)
+		row("---", "---", "---")
+		for _, c := range d.Cards {
+			row(c.Card, c.A, c.B)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,100 @@
+package mtgcount
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	cards := loadTestCards(t, "testdata/oracle-cards.json")
+	got := Stats(cards, []string{"legacy", "vintage", "standard"})
+	want := []FormatStats{
+		{"legacy", 8, 0, 2, 3, map[string]int{"Creature": 4, "Instant": 3, "Land": 1}},
+		{"vintage", 8, 2, 0, 3, map[string]int{"Artifact": 1, "Creature": 4, "Instant": 4, "Land": 1}},
+		{"standard", 2, 0, 0, 1, map[string]int{"Instant": 1, "Land": 1}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Stats=%+v; want %+v", got, want)
+	}
+}
+
+func TestDiffFormats(t *testing.T) {
+	cards := loadTestCards(t, "testdata/oracle-cards.json")
+	cases := []struct {
+		a, b string
+		want []LegalityDiff
+	}{
+		{"modern", "pioneer", []LegalityDiff{
+			{"Lightning Bolt", "Legal", NotLegalStatus},
+			{"Nazgûl", "Legal", NotLegalStatus},
+			{"Relentless Rats", "Legal", NotLegalStatus},
+		}},
+		{"legacy", "vintage", []LegalityDiff{
+			{"Ancestral Recall", "Banned", "Restricted"},
+			{"Black Lotus", "Banned", "Restricted"},
+		}},
+		{"legacy", "commander", []LegalityDiff{}},
+	}
+	for _, c := range cases {
+		if got := DiffFormats(cards, c.a, c.b); got.A != c.a || got.B != c.b || !reflect.DeepEqual(got.Cards, c.want) {
+			t.Errorf("DiffFormats(%s, %s)=%+v; want %+v", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	r := Report{
+		Formats: []FormatStats{
+			{"legacy", 8, 0, 2, 3, map[string]int{"Creature": 4, "Land": 1}},
+			{"standard", 2, 0, 0, 1, map[string]int{"Land": 1}},
+		},
+		Diffs: []FormatDiff{
+			{"legacy", "vintage", []LegalityDiff{{"Black Lotus", "Banned", "Restricted"}}},
+			{"legacy", "commander", []LegalityDiff{}},
+		},
+	}
+	want := `## Formats
+
+| format | legal | restricted | banned | unlimited |
+| --- | ---: | ---: | ---: | ---: |
+| legacy | 8 | 0 | 2 | 3 |
+| standard | 2 | 0 | 0 | 1 |
+
+## Card types
+
+| type | legacy | standard |
+| --- | ---: | ---: |
+| Creature | 4 | 0 |
+| Land | 1 | 1 |
+
+## legacy vs. vintage
+
+| card | legacy | vintage |
+| --- | --- | --- |
+| Black Lotus | Banned | Restricted |
+
+## legacy vs. commander
+
+No differences.
+`
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, "markdown", r); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("WriteReport(markdown)=\n%s; want\n%s", got, want)
+	}
+	buf.Reset()
+	if err := WriteReport(&buf, "json", r); err != nil {
+		t.Fatal(err)
+	}
+	var got Report
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil || !reflect.DeepEqual(got, r) {
+		t.Errorf("WriteReport(json)=%s (%v); want %+v", buf.Bytes(), err, r)
+	}
+	if err := WriteReport(&bytes.Buffer{}, "csv", r); err == nil {
+		t.Errorf("WriteReport(\"csv\") succeeded; want error")
+	}
+}
@@ -0,0 +1,125 @@
+package mtgcount
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// A Rule defines a format that the card data doesn't have, like pauper by
+// printings, peasant or a cube, by which cards are in it.  A card is legal
+// if it was printed at one of Rarities and in one of Sets, and is legal in
+// Base, where these are given; restricted if it is restricted in Base or
+// listed in Restricted; and banned if it is banned in Base or listed in
+// Banned.
+type Rule struct {
+	Name               string
+	Rarities, Sets     []string
+	Base               string
+	Banned, Restricted []string
+}
+
+// ReadRules reads a JSON array of Rules, like
+//
+//	[{"name": "peasant", "rarities": ["common", "uncommon"], "banned": ["Sol Ring"]}]
+func ReadRules(r io.Reader) ([]Rule, error) {
+	var rules []Rule
+	if err := json.NewDecoder(r).Decode(&rules); err != nil {
+		return nil, fmt.Errorf("mtgcount: reading rules: %v", err)
+	}
+	for i, rule := range rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("mtgcount: rule %d has no name", i+1)
+		}
+	}
+	return rules, nil
+}
+
+// ApplyRules adds the formats of rules to the legalities of cards,
+// replacing any formats of the same names, so that they can be counted like
+// the card data's own.  It is an error for a rule to ban or restrict a card
+// that doesn't exist, or to need rarities the card data doesn't have.
+func ApplyRules(cards []Card, rules []Rule) error {
+	index := map[string]int{}
+	hasRarities := false
+	for i, c := range cards {
+		index[c.Name] = i
+		hasRarities = hasRarities || len(c.Rarities) > 0
+	}
+	for _, rule := range rules {
+		if len(rule.Rarities) > 0 && !hasRarities {
+			return fmt.Errorf("mtgcount: rule %s needs rarities, which only Scryfall and mtgjson AllPrintings card data have", rule.Name)
+		}
+		for _, name := range append(append([]string{}, rule.Banned...), rule.Restricted...) {
+			if _, ok := index[name]; !ok {
+				return fmt.Errorf("mtgcount: rule %s: unknown card %q", rule.Name, name)
+			}
+		}
+		for i := range cards {
+			c := &cards[i]
+			if c.Legalities == nil {
+				c.Legalities = map[string]string{}
+			}
+			delete(c.Legalities, rule.Name)
+			if leg := rule.legality(*c); leg != "" {
+				c.Legalities[rule.Name] = leg
+			}
+		}
+	}
+	return nil
+}
+
+// ReadCardFileWithRules reads the cards in the file at path, as ReadCardFile
+// does, and if rulesPath isn't empty, applies the rules in the file at
+// rulesPath to them, as ReadRules and ApplyRules do.
+func ReadCardFileWithRules(path, rulesPath string) ([]Card, error) {
+	cards, err := ReadCardFile(path)
+	if err != nil || rulesPath == "" {
+		return cards, err
+	}
+	f, err := os.Open(rulesPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	rules, err := ReadRules(f)
+	if err != nil {
+		return nil, err
+	}
+	if err := ApplyRules(cards, rules); err != nil {
+		return nil, err
+	}
+	return cards, nil
+}
+
+// legality returns c's legality in the rule's format, or "" if c isn't in
+// it.
+func (rule Rule) legality(c Card) string {
+	if len(rule.Rarities) > 0 && !containsAny(c.Rarities, rule.Rarities) ||
+		len(rule.Sets) > 0 && !containsAny(c.Printings, rule.Sets) {
+		return ""
+	}
+	leg := "Legal"
+	if rule.Base != "" {
+		if leg = c.Legalities[rule.Base]; leg == "" {
+			return ""
+		}
+	}
+	switch {
+	case contains(rule.Banned, c.Name):
+		return "Banned"
+	case contains(rule.Restricted, c.Name) && leg == "Legal":
+		return "Restricted"
+	}
+	return leg
+}
+
+func containsAny(list, of []string) bool {
+	for _, s := range of {
+		if contains(list, s) {
+			return true
+		}
+	}
+	return false
+}
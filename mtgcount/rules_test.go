@@ -0,0 +1,84 @@
+package mtgcount
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplyRules(t *testing.T) {
+	f, err := os.Open("testdata/rules.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rules, err := ReadRules(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"testdata/oracle-cards.json", "testdata/AllPrintings.json"} {
+		cards := loadTestCards(t, path)
+		if err := ApplyRules(cards, rules); err != nil {
+			t.Fatalf("ApplyRules(%s): %v", path, err)
+		}
+		cases := []struct {
+			format string
+			names  []string
+			limit  []int
+		}{
+			{"commons", []string{"Counterspell", "Island", "Lightning Bolt", "Persistent Petitioners", "Seven Dwarves", "Shock"},
+				[]int{4, Unlimited, 4, Unlimited, 7, 4}},
+			{"peasant", []string{"Counterspell", "Island", "Lightning Bolt", "Nazgûl", "Relentless Rats", "Seven Dwarves", "Shock"},
+				[]int{4, Unlimited, 4, 9, Unlimited, 7, 4}},
+			{"classic", []string{"Ancestral Recall", "Black Lotus", "Counterspell", "Island", "Lightning Bolt", "Relentless Rats", "Shock"},
+				[]int{1, 1, 4, Unlimited, 1, Unlimited, 4}},
+		}
+		for _, c := range cases {
+			names, limit := FormatCards(cards, c.format)
+			if !reflect.DeepEqual(names, c.names) || !reflect.DeepEqual(limit, c.limit) {
+				t.Errorf("%s: FormatCards(%q)=%v, %v; want %v, %v", path, c.format, names, limit, c.names, c.limit)
+			}
+		}
+		if got := cards[6].Legalities["peasant"]; got != "Banned" {
+			t.Errorf("%s: %s is %q in peasant; want Banned", path, cards[6].Name, got)
+		}
+	}
+}
+
+func TestReadCardFileWithRules(t *testing.T) {
+	cards, err := ReadCardFileWithRules("testdata/oracle-cards.json", "testdata/rules.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cards[6].Legalities["peasant"]; got != "Banned" {
+		t.Errorf("ReadCardFileWithRules: %s is %q in peasant; want Banned", cards[6].Name, got)
+	}
+	if cards, err := ReadCardFileWithRules("testdata/oracle-cards.json", ""); err != nil || cards[6].Legalities["peasant"] != "" {
+		t.Errorf("ReadCardFileWithRules(no rules)=%v; want no peasant format", err)
+	}
+	if _, err := ReadCardFileWithRules("testdata/AtomicCards.json", "testdata/rules.json"); err == nil {
+		t.Errorf("ReadCardFileWithRules(AtomicCards.json) succeeded; want an error for rules needing rarities")
+	}
+}
+
+func TestApplyRulesErrors(t *testing.T) {
+	cases := []struct {
+		path, rules string
+		want        string
+	}{
+		{"testdata/AtomicCards.json", `[{"name": "commons", "rarities": ["common"]}]`, "needs rarities"},
+		{"testdata/oracle-cards.json", `[{"name": "peasant", "banned": ["Sol Ring"]}]`, "unknown card"},
+		{"testdata/oracle-cards.json", `[{"rarities": ["common"]}]`, "no name"},
+		{"testdata/oracle-cards.json", `{"name": "peasant"}`, "reading rules"},
+	}
+	for _, c := range cases {
+		rules, err := ReadRules(strings.NewReader(c.rules))
+		if err == nil {
+			err = ApplyRules(loadTestCards(t, c.path), rules)
+		}
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("ApplyRules(%s, %s)=%v; want error containing %q", c.path, c.rules, err, c.want)
+		}
+	}
+}
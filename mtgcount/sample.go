@@ -0,0 +1,88 @@
+package mtgcount
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"math/rand"
+)
+
+var ErrNoDecks = errors.New("mtgcount: no legal decks")
+
+// Sampler draws uniformly random decks from the decks CountDecks counts.  It
+// also numbers the decks; see Rank.
+type Sampler struct {
+	numMain, numSide int
+	names            []string
+	index            map[string]int // Index of each name.
+	limit            []int
+	groups           []*group
+	// suffix[g] is the generating function of groups[g:].
+	suffix []poly
+}
+
+// NewSampler returns a sampler of decks with numMain cards in the main deck
+// and numSide in the sideboard, made from cards with the given names and
+// limits, as in CountDecks.  If there are no such decks, it returns
+// ErrNoDecks.
+func NewSampler(numMain, numSide int, names []string, limit []int) (*Sampler, error) {
+	return NewSamplerContext(context.Background(), numMain, numSide, names, limit)
+}
+
+// NewSamplerContext is like NewSampler, but stops early if ctx is done,
+// returning its error.
+func NewSamplerContext(ctx context.Context, numMain, numSide int, names []string, limit []int) (*Sampler, error) {
+	if len(names) != len(limit) {
+		return nil, errors.New("mtgcount: names and limits differ in length")
+	}
+	if numMain < 0 || numSide < 0 {
+		return nil, ErrNoDecks
+	}
+	gs, err := groupsContext(ctx, numMain, numSide, limit)
+	if err != nil {
+		return nil, err
+	}
+	sm := &Sampler{numMain: numMain, numSide: numSide, names: names, index: map[string]int{}, limit: limit, groups: gs}
+	for i, name := range names {
+		sm.index[name] = i
+	}
+	sm.suffix = make([]poly, len(sm.groups)+1)
+	sm.suffix[len(sm.groups)] = one(numMain, numSide)
+	for g := len(sm.groups) - 1; g >= 0; g-- {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		sm.suffix[g] = sm.groups[g].poly.mul(sm.suffix[g+1])
+	}
+	if sm.Count().Sign() == 0 {
+		return nil, ErrNoDecks
+	}
+	return sm, nil
+}
+
+// Count returns the number of decks the sampler draws from.
+func (sm *Sampler) Count() *big.Int {
+	return sm.suffix[0][sm.numMain][sm.numSide]
+}
+
+// Sample returns a uniformly random deck, as the names of the cards in the
+// main deck and sideboard, one per copy, in the order the cards were given to
+// NewSampler.
+func (sm *Sampler) Sample(r *rand.Rand) (main, side []string) {
+	main, side, _ = sm.Unrank(new(big.Int).Rand(r, sm.Count()))
+	return main, side
+}
+
+// FormatCards returns the names of the cards legal or restricted in format,
+// and the number of copies of each a deck can have, as for NewSampler.
+func FormatCards(cards []Card, format string) (names []string, limit []int) {
+	for _, c := range cards {
+		switch c.Legalities[format] {
+		case "Legal":
+			names, limit = append(names, c.Name), append(limit, FormatLimit(format)(c))
+		case "Restricted":
+			names, limit = append(names, c.Name), append(limit, 1)
+		}
+	}
+	return names, limit
+}
@@ -0,0 +1,70 @@
+package mtgcount
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSample(t *testing.T) {
+	names := []string{"a", "b", "c"}
+	limit := []int{1, 2, 3}
+	sm, err := NewSampler(3, 1, names, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sm.Count().Int64(); got != 12 {
+		t.Errorf("Count()=%d; want 12", got)
+	}
+	r := rand.New(rand.NewSource(1))
+	const n = 12000
+	seen := map[string]int{}
+	for i := 0; i < n; i++ {
+		main, side := sm.Sample(r)
+		if len(main) != 3 || len(side) != 1 {
+			t.Fatalf("Sample()=%v/%v; want 3/1 cards", main, side)
+		}
+		copies := map[string]int{}
+		for _, name := range append(append([]string{}, main...), side...) {
+			copies[name]++
+		}
+		for i, name := range names {
+			if copies[name] > limit[i] {
+				t.Fatalf("Sample()=%v/%v; has %d copies of %s", main, side, copies[name], name)
+			}
+		}
+		seen[strings.Join(main, "")+"/"+strings.Join(side, "")]++
+	}
+	if len(seen) != 12 {
+		t.Errorf("Sample() drew %d distinct decks; want 12: %v", len(seen), seen)
+	}
+	for deck, count := range seen {
+		if count < n/12*8/10 || count > n/12*12/10 {
+			t.Errorf("Sample() drew %s %d times in %d; want about %d", deck, count, n, n/12)
+		}
+	}
+}
+
+func TestSampleSeed(t *testing.T) {
+	cards := loadTestCards(t, "testdata/AtomicCards.json")
+	names, limit := FormatCards(cards, "vintage")
+	sm, err := NewSampler(60, 15, names, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	main1, side1 := sm.Sample(rand.New(rand.NewSource(7)))
+	main2, side2 := sm.Sample(rand.New(rand.NewSource(7)))
+	if !reflect.DeepEqual(main1, main2) || !reflect.DeepEqual(side1, side2) {
+		t.Errorf("Sample() with the same seed gave %v/%v and %v/%v", main1, side1, main2, side2)
+	}
+	if len(main1) != 60 || len(side1) != 15 {
+		t.Errorf("Sample()=%d/%d cards; want 60/15", len(main1), len(side1))
+	}
+}
+
+func TestNewSamplerNoDecks(t *testing.T) {
+	if _, err := NewSampler(60, 15, []string{"Island"}, []int{74}); err != ErrNoDecks {
+		t.Errorf("NewSampler(60, 15, [Island], [74]) error=%v; want %v", err, ErrNoDecks)
+	}
+}
@@ -0,0 +1,392 @@
+// Package server serves deck counts, format summaries, random decks and
+// deck validation over HTTP as JSON, from card data loaded once.
+//
+// Every endpoint takes its parameters in the query string and answers with
+// a JSON object, or with {"error": message} and a 4xx status, or 503 if
+// counting or sampling takes longer than the server's timeout.  Exact counts
+// and samples are limited to MaxExactCards cards, and approximate counts to
+// MaxApproxCards and MaxApproxCost:
+//
+//	GET  /formats                                  the cards of each format, by copy limit
+//	GET  /count?format=F&main=60&side=15           the number of decks, as a string of digits
+//	GET  /count?limits=1,4,4,unlimited&main=60     the same, for a custom card pool
+//	GET  /count?format=F&approx=true               log10 of the number of decks, within a bound
+//	GET  /sample?format=F&main=60&side=15&n=1&seed=S  uniformly random decks
+//	POST /validate?format=F&main=60&side=15        the violations of the deck list in the body
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jordancurve/games/mtgcount"
+)
+
+// Bounds on the work of one request.  Exact counts and samples take time
+// growing steeply with the size of the deck, so above MaxExactCards callers
+// must ask for an approximate count.
+const (
+	// MaxExactCards is the largest main deck plus sideboard the server
+	// counts exactly or samples.
+	MaxExactCards = 100
+	// MaxApproxCards and MaxApproxSide bound the decks the server counts
+	// approximately.
+	MaxApproxCards = 1000
+	MaxApproxSide  = 100
+	// MaxApproxCost bounds mtgcount.CountDecksLog10Cost of an approximate
+	// count, which grows with the number of distinct copy limits, and is
+	// a few seconds of work.
+	MaxApproxCost = 3e9
+	// MaxSamples is the most decks one /sample request returns.
+	MaxSamples = 100
+	// DefaultTimeout is the default Server.Timeout.
+	DefaultTimeout = 30 * time.Second
+	// maxCounts is the most counts, generating functions and samplers the
+	// server remembers.
+	maxCounts = 1000
+)
+
+// Server is an http.Handler answering requests about a set of cards.
+type Server struct {
+	// Timeout bounds the time the server spends counting or sampling for one
+	// request.
+	Timeout time.Duration
+	cards   []mtgcount.Card
+	limits  map[string][]int
+	counter mtgcount.Counter
+	mux     *http.ServeMux
+}
+
+// New returns a server of the given cards, which it keeps.
+func New(cards []mtgcount.Card) *Server {
+	s := &Server{Timeout: DefaultTimeout, cards: cards, limits: mtgcount.Limits(cards), mux: http.NewServeMux()}
+	s.counter.MaxEntries = maxCounts
+	s.mux.HandleFunc("/formats", s.method("GET", s.formats))
+	s.mux.HandleFunc("/count", s.method("GET", s.count))
+	s.mux.HandleFunc("/sample", s.method("GET", s.sample))
+	s.mux.HandleFunc("/validate", s.method("POST", s.validate))
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// httpError is an error with the status to answer it with.
+type httpError struct {
+	status int
+	msg    string
+}
+
+func (e *httpError) Error() string { return e.msg }
+
+func badRequest(format string, args ...interface{}) error {
+	return &httpError{http.StatusBadRequest, fmt.Sprintf(format, args...)}
+}
+
+// method adapts a handler returning the value to answer with, or an error,
+// to an http.HandlerFunc that only accepts the given method.
+func (s *Server) method(method string, h func(r *http.Request) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var v interface{}
+		err := error(&httpError{http.StatusMethodNotAllowed, "method not allowed"})
+		if r.Method == method {
+			v, err = h(r)
+		} else {
+			w.Header().Set("Allow", method)
+		}
+		status := http.StatusOK
+		if err != nil {
+			status = http.StatusInternalServerError
+			if he, ok := err.(*httpError); ok {
+				status = he.status
+			}
+			v = map[string]string{"error": err.Error()}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(v)
+	}
+}
+
+// intParam returns the query parameter name as an int in [0, max], or def
+// if it's missing.
+func intParam(r *http.Request, name string, def, max int) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 || n > max {
+		return 0, badRequest("%s must be an integer from 0 to %d, not %q", name, max, v)
+	}
+	return n, nil
+}
+
+// deckSize returns the main and side parameters, 60 and 15 by default,
+// which together may be at most max.
+func deckSize(r *http.Request, max int) (main, side int, err error) {
+	if main, err = intParam(r, "main", 60, max); err != nil {
+		return 0, 0, err
+	}
+	if side, err = intParam(r, "side", 15, max); err != nil {
+		return 0, 0, err
+	}
+	if main+side > max {
+		return 0, 0, badRequest("main+side must be at most %d", max)
+	}
+	return main, side, nil
+}
+
+// exactDeckSize is deckSize for exact counts and samples.
+func exactDeckSize(r *http.Request) (main, side int, err error) {
+	main, side, err = deckSize(r, MaxApproxCards)
+	if err == nil && main+side > MaxExactCards {
+		err = badRequest("main+side must be at most %d, or use approx=true to count up to %d", MaxExactCards, MaxApproxCards)
+	}
+	return main, side, err
+}
+
+// format returns the format parameter, which must be a known format.
+func (s *Server) format(r *http.Request) (string, error) {
+	f := r.URL.Query().Get("format")
+	if f == "" {
+		return "", badRequest("missing format")
+	}
+	if _, ok := s.limits[f]; !ok {
+		return "", &httpError{http.StatusNotFound, fmt.Sprintf("unknown format %q", f)}
+	}
+	return f, nil
+}
+
+// FormatSummary is how many cards a format has with each copy limit.
+type FormatSummary struct {
+	Format string         `json:"format"`
+	Cards  int            `json:"cards"`
+	Limits map[string]int `json:"limits"` // By limit, or "unlimited".
+}
+
+func (s *Server) formats(r *http.Request) (interface{}, error) {
+	summaries := []FormatSummary{}
+	for f, limit := range s.limits {
+		sum := FormatSummary{Format: f, Cards: len(limit), Limits: map[string]int{}}
+		for _, lim := range limit {
+			sum.Limits[limitName(lim)]++
+		}
+		summaries = append(summaries, sum)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Format < summaries[j].Format })
+	return map[string]interface{}{"formats": summaries}, nil
+}
+
+func limitName(lim int) string {
+	if lim == mtgcount.Unlimited {
+		return "unlimited"
+	}
+	return strconv.Itoa(lim)
+}
+
+// CountResult is the answer to /count.  Decks is set unless the count is
+// approximate, when Log10 and Bound are: log10 of the count is within Bound
+// of Log10.
+type CountResult struct {
+	Format string   `json:"format,omitempty"`
+	Main   int      `json:"main"`
+	Side   int      `json:"side"`
+	Decks  string   `json:"decks,omitempty"`
+	Log10  *float64 `json:"log10,omitempty"`
+	Bound  *float64 `json:"bound,omitempty"`
+}
+
+func (s *Server) count(r *http.Request) (interface{}, error) {
+	approx, _ := strconv.ParseBool(r.URL.Query().Get("approx"))
+	main, side, err := exactDeckSize(r)
+	if approx {
+		main, side, err = deckSize(r, MaxApproxCards)
+		if err == nil && side > MaxApproxSide {
+			err = badRequest("side must be at most %d", MaxApproxSide)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	res := CountResult{Main: main, Side: side}
+	var limit []int
+	if l := r.URL.Query().Get("limits"); l != "" {
+		for _, f := range strings.Split(l, ",") {
+			lim, err := strconv.Atoi(strings.TrimSpace(f))
+			if strings.TrimSpace(f) == "unlimited" {
+				lim, err = mtgcount.Unlimited, nil
+			}
+			if err != nil || lim < 0 {
+				return nil, badRequest("limits must be copy limits or \"unlimited\", not %q", f)
+			}
+			limit = append(limit, lim)
+		}
+	} else {
+		if res.Format, err = s.format(r); err != nil {
+			return nil, err
+		}
+		limit = s.limits[res.Format]
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), s.Timeout)
+	defer cancel()
+	if approx {
+		if mtgcount.CountDecksLog10Cost(main, side, limit) > MaxApproxCost {
+			return nil, badRequest("too many distinct copy limits for a %d+%d card deck; try a smaller deck", main, side)
+		}
+		log10, e, err := mtgcount.CountDecksLog10Context(ctx, main, side, limit)
+		if err == context.DeadlineExceeded {
+			return nil, &httpError{http.StatusServiceUnavailable, fmt.Sprintf("counting took over %v; try a smaller deck", s.Timeout)}
+		} else if err != nil {
+			return nil, err
+		}
+		if math.IsInf(log10, -1) {
+			res.Decks = "0"
+		} else {
+			res.Log10, res.Bound = &log10, &e
+		}
+		return res, nil
+	}
+	n, err := s.counter.Count(ctx, main, side, limit)
+	if err == context.DeadlineExceeded {
+		return nil, &httpError{http.StatusServiceUnavailable, fmt.Sprintf("counting took over %v; try approx=true", s.Timeout)}
+	} else if err != nil {
+		return nil, err
+	}
+	res.Decks = n.String()
+	return res, nil
+}
+
+// Entry is a number of copies of a card, as in mtgcount.Entry.
+type Entry struct {
+	Count int    `json:"count"`
+	Name  string `json:"name"`
+}
+
+// Deck is a main deck and sideboard.
+type Deck struct {
+	Main []Entry `json:"main"`
+	Side []Entry `json:"side"`
+}
+
+// SampleResult is the answer to /sample.  Seed reproduces the decks.
+type SampleResult struct {
+	Format string `json:"format"`
+	Seed   int64  `json:"seed"`
+	Decks  []Deck `json:"decks"`
+}
+
+func (s *Server) sample(r *http.Request) (interface{}, error) {
+	f, err := s.format(r)
+	if err != nil {
+		return nil, err
+	}
+	main, side, err := exactDeckSize(r)
+	if err != nil {
+		return nil, err
+	}
+	n, err := intParam(r, "n", 1, MaxSamples)
+	if err != nil {
+		return nil, err
+	}
+	res := SampleResult{Format: f, Seed: time.Now().UnixNano(), Decks: []Deck{}}
+	if v := r.URL.Query().Get("seed"); v != "" {
+		if res.Seed, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return nil, badRequest("seed must be an integer, not %q", v)
+		}
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), s.Timeout)
+	defer cancel()
+	names, limit := mtgcount.FormatCards(s.cards, f)
+	sm, err := s.counter.Sampler(ctx, f, main, side, names, limit)
+	if err == mtgcount.ErrNoDecks {
+		return nil, badRequest("%s has no %d+%d card decks", f, main, side)
+	} else if err == context.DeadlineExceeded {
+		return nil, &httpError{http.StatusServiceUnavailable, fmt.Sprintf("sampling took over %v; try a smaller deck", s.Timeout)}
+	} else if err != nil {
+		return nil, err
+	}
+	rnd := rand.New(rand.NewSource(res.Seed))
+	for i := 0; i < n; i++ {
+		m, sb := sm.Sample(rnd)
+		res.Decks = append(res.Decks, Deck{entries(m), entries(sb)})
+	}
+	return res, nil
+}
+
+// entries groups a list of card names, one per copy, into entries.
+func entries(names []string) []Entry {
+	list := []Entry{}
+	for i := 0; i < len(names); {
+		j := i
+		for j < len(names) && names[j] == names[i] {
+			j++
+		}
+		list = append(list, Entry{j - i, names[i]})
+		i = j
+	}
+	return list
+}
+
+// Violation is an mtgcount.Violation, with its kind named.
+type Violation struct {
+	Kind    string `json:"kind"`
+	Card    string `json:"card,omitempty"`
+	Message string `json:"message"`
+}
+
+var violationKinds = map[mtgcount.ViolationKind]string{
+	mtgcount.DeckSize:      "deck size",
+	mtgcount.UnknownCard:   "unknown card",
+	mtgcount.NotLegal:      "not legal",
+	mtgcount.Banned:        "banned",
+	mtgcount.Restricted:    "restricted",
+	mtgcount.TooManyCopies: "too many copies",
+}
+
+// ValidateResult is the answer to /validate.
+type ValidateResult struct {
+	Format     string      `json:"format"`
+	Valid      bool        `json:"valid"`
+	Violations []Violation `json:"violations"`
+}
+
+// maxDeckList is the largest deck list /validate reads.
+const maxDeckList = 1 << 20
+
+func (s *Server) validate(r *http.Request) (interface{}, error) {
+	f, err := s.format(r)
+	if err != nil {
+		return nil, err
+	}
+	main, side, err := deckSize(r, MaxApproxCards)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(http.MaxBytesReader(nil, r.Body, maxDeckList))
+	if err != nil {
+		return nil, badRequest("reading deck list: %v", err)
+	}
+	deck, err := mtgcount.ParseDeckList(data)
+	if err != nil {
+		return nil, badRequest("%v", err)
+	}
+	res := ValidateResult{Format: f, Violations: []Violation{}}
+	for _, v := range mtgcount.Validate(deck, s.cards, f, main, side) {
+		res.Violations = append(res.Violations, Violation{violationKinds[v.Kind], v.Card, v.Message})
+	}
+	res.Valid = len(res.Violations) == 0
+	return res, nil
+}
@@ -0,0 +1,207 @@
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jordancurve/games/mtgcount"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	cards, err := mtgcount.ReadCardFile("../testdata/oracle-cards.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(New(cards))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// do makes a request, checks its status, and decodes its JSON answer into v.
+func do(t *testing.T, ts *httptest.Server, method, path, body string, status int, v interface{}) {
+	t.Helper()
+	req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != status {
+		t.Errorf("%s %s: status %d (%s); want %d", method, path, resp.StatusCode, data, status)
+		return
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("%s %s: Content-Type %q; want application/json", method, path, ct)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Errorf("%s %s: %v in %s", method, path, err, data)
+	}
+}
+
+func TestFormats(t *testing.T) {
+	ts := newTestServer(t)
+	var got struct{ Formats []FormatSummary }
+	do(t, ts, "GET", "/formats", "", http.StatusOK, &got)
+	if len(got.Formats) != 7 {
+		t.Fatalf("GET /formats=%+v; want 7 formats", got)
+	}
+	want := FormatSummary{"vintage", 10, map[string]int{"1": 2, "4": 3, "7": 1, "9": 1, "unlimited": 3}}
+	if !reflect.DeepEqual(got.Formats[6], want) {
+		t.Errorf("GET /formats: vintage=%+v; want %+v", got.Formats[6], want)
+	}
+}
+
+func TestCount(t *testing.T) {
+	ts := newTestServer(t)
+	cases := []struct {
+		path   string
+		status int
+		want   string
+	}{
+		{"/count?format=legacy", http.StatusOK, "282748800620"},
+		{"/count?format=standard&main=60&side=15", http.StatusOK, "15"},
+		{"/count?limits=1,2,3&main=3&side=0", http.StatusOK, "6"},
+		{"/count?limits=1,unlimited&main=4&side=1", http.StatusOK, "3"},
+		{"/count?format=standard&main=100&side=0", http.StatusOK, "5"},
+		{"/count?format=standard&main=101&side=0", http.StatusBadRequest, ""},
+		{"/count?format=standard&main=1001&side=0&approx=true", http.StatusBadRequest, ""},
+		{"/count?format=standard&main=500&side=101&approx=true", http.StatusBadRequest, ""},
+		{"/count?format=standard&main=-1", http.StatusBadRequest, ""},
+		{"/count?limits=1,x", http.StatusBadRequest, ""},
+		{"/count", http.StatusBadRequest, ""},
+		{"/count?format=historic", http.StatusNotFound, ""},
+	}
+	for _, c := range cases {
+		var got CountResult
+		do(t, ts, "GET", c.path, "", c.status, &got)
+		if got.Decks != c.want {
+			t.Errorf("GET %s=%+v; want %s decks", c.path, got, c.want)
+		}
+	}
+	var got CountResult
+	do(t, ts, "GET", "/count?format=legacy&approx=true", "", http.StatusOK, &got)
+	if got.Log10 == nil || got.Bound == nil || *got.Bound > 1e-9 || *got.Log10-11.451401 > 1e-6 || 11.451401-*got.Log10 > 1e-6 {
+		t.Errorf("GET /count?format=legacy&approx=true=%+v; want log10 11.451401", got)
+	}
+	got = CountResult{}
+	do(t, ts, "GET", "/count?format=legacy&main=900&side=100&approx=true", "", http.StatusOK, &got)
+	if got.Log10 == nil || got.Bound == nil || *got.Bound > 1e-6 {
+		t.Errorf("GET /count?format=legacy&main=900&side=100&approx=true=%+v; want log10 within 1e-6", got)
+	}
+	var e map[string]string
+	do(t, ts, "POST", "/count?format=legacy", "", http.StatusMethodNotAllowed, &e)
+	if e["error"] == "" {
+		t.Errorf("POST /count=%v; want an error", e)
+	}
+}
+
+func TestCountTimeout(t *testing.T) {
+	cards, err := mtgcount.ReadCardFile("../testdata/oracle-cards.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New(cards)
+	s.Timeout = time.Nanosecond
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	var e map[string]string
+	do(t, ts, "GET", "/count?format=legacy", "", http.StatusServiceUnavailable, &e)
+	if !strings.Contains(e["error"], "approx=true") {
+		t.Errorf("GET /count with a timeout=%v; want an error suggesting approx=true", e)
+	}
+	do(t, ts, "GET", "/count?format=legacy&main=900&side=100&approx=true", "", http.StatusServiceUnavailable, &e)
+	do(t, ts, "GET", "/sample?format=legacy", "", http.StatusServiceUnavailable, &e)
+}
+
+func TestCountApproxCost(t *testing.T) {
+	ts := newTestServer(t)
+	// Three groups of 20 cards with different limits of 20 or so, besides
+	// many playsets, make the approximation multiply dense polynomials for
+	// many seconds.
+	limits := []string{}
+	for i := 0; i < 60; i++ {
+		limits = append(limits, strconv.Itoa(20+i/20))
+	}
+	for i := 0; i < 1000; i++ {
+		limits = append(limits, "4")
+	}
+	path := "/count?limits=" + strings.Join(limits, ",") + "&main=900&side=100&approx=true"
+	var e map[string]string
+	do(t, ts, "GET", path, "", http.StatusBadRequest, &e)
+	if !strings.Contains(e["error"], "copy limits") {
+		t.Errorf("GET %s=%v; want an error about copy limits", path, e)
+	}
+}
+
+func TestSample(t *testing.T) {
+	ts := newTestServer(t)
+	var got SampleResult
+	do(t, ts, "GET", "/sample?format=vintage&main=60&side=15&n=3&seed=1", "", http.StatusOK, &got)
+	if got.Seed != 1 || len(got.Decks) != 3 {
+		t.Fatalf("GET /sample=%+v; want 3 decks with seed 1", got)
+	}
+	var again SampleResult
+	do(t, ts, "GET", "/sample?format=vintage&main=60&side=15&n=3&seed=1", "", http.StatusOK, &again)
+	if !reflect.DeepEqual(got, again) {
+		t.Errorf("GET /sample with the same seed=%+v, then %+v", got, again)
+	}
+	for _, d := range got.Decks {
+		main, side := 0, 0
+		for _, e := range d.Main {
+			main += e.Count
+		}
+		for _, e := range d.Side {
+			side += e.Count
+		}
+		if main != 60 || side != 15 {
+			t.Errorf("GET /sample: %+v has %d+%d cards; want 60+15", d, main, side)
+		}
+	}
+	var e map[string]string
+	do(t, ts, "GET", "/sample?main=60", "", http.StatusBadRequest, &e)
+	do(t, ts, "GET", "/sample?format=vintage&n=101", "", http.StatusBadRequest, &e)
+	do(t, ts, "GET", "/sample?format=vintage&main=90&side=15", "", http.StatusBadRequest, &e)
+	do(t, ts, "GET", "/sample?format=vintage&seed=x", "", http.StatusBadRequest, &e)
+}
+
+func TestValidate(t *testing.T) {
+	ts := newTestServer(t)
+	legal := "20 Island\n4 Counterspell\n4 Lightning Bolt\n20 Relentless Rats\n4 Shock\n8 Persistent Petitioners\n\nSideboard\n15 Island\n"
+	cases := []struct {
+		path, deck string
+		valid      bool
+		kinds      []string
+	}{
+		{"/validate?format=legacy", legal, true, nil},
+		{"/validate?format=legacy", legal + "1 Black Lotus\n2 Shock\n", false, []string{"deck size", "too many copies", "banned"}},
+		{"/validate?format=pauper&main=40&side=0", legal, false, []string{"deck size", "not legal"}},
+	}
+	for _, c := range cases {
+		var got ValidateResult
+		do(t, ts, "POST", c.path, c.deck, http.StatusOK, &got)
+		kinds := []string{}
+		for _, v := range got.Violations {
+			kinds = append(kinds, v.Kind)
+		}
+		if got.Valid != c.valid || !reflect.DeepEqual(kinds, append([]string{}, c.kinds...)) {
+			t.Errorf("POST %s=%+v; want valid %v, violations %v", c.path, got, c.valid, c.kinds)
+		}
+	}
+	var e map[string]string
+	do(t, ts, "GET", "/validate?format=legacy", "", http.StatusMethodNotAllowed, &e)
+	do(t, ts, "POST", "/validate?format=historic", legal, http.StatusNotFound, &e)
+}
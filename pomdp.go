@@ -0,0 +1,407 @@
+package mdp
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+POMDP represents a partially observable Markov Decision Process.  The
+dynamics are those of the underlying MDP, with the same Nature and Player1
+states, but the player can't see which state the token is on.  Instead,
+whenever the token moves to a state s, the player receives a signal drawn at
+random according to the distribution Obs[s].  A state with no observations
+always emits signal 0.
+
+Since the player may not even know whose turn it is, the player makes a
+choice on every turn.  If the token is on a player state, it follows
+Action[choice]; if it is on a nature state, nature picks an action as usual
+and the choice is ignored.  The choices available from a belief are those
+that are valid in every player state the token might be on.  When the token
+reaches a state with no actions the game ends, although the player may not
+know it yet: the token stays put, earns nothing more and keeps emitting
+signals.
+*/
+type POMDP struct {
+	MDP
+	Obs [][]Observation
+}
+
+type Observation struct {
+	Signal uint
+	Prob   float64
+}
+
+// Belief is a probability distribution over the states of a POMDP.
+type Belief []float64
+
+var ErrTooManyBeliefs = errors.New("mdp: too many reachable beliefs")
+
+// Start returns the belief that the token is on state s.
+func (p POMDP) Start(s uint) Belief {
+	b := make(Belief, len(p.MDP))
+	b[s] = 1
+	return b
+}
+
+// Choices returns the number of choices available from belief b.  It is zero
+// if the game is certainly over.
+func (p POMDP) Choices(b Belief) int {
+	n, over := -1, true
+	for s, w := range b {
+		state := p.MDP[s]
+		if w == 0 || len(state.Action) == 0 {
+			continue
+		}
+		over = false
+		if state.Player == Player1 && (n < 0 || len(state.Action) < n) {
+			n = len(state.Action)
+		}
+	}
+	switch {
+	case over:
+		return 0
+	case n < 0:
+		return 1
+	}
+	return n
+}
+
+// Update returns the belief after the player makes the given choice from
+// belief b and then receives signal, along with the probability of receiving
+// that signal.  If the signal is impossible, Update returns nil and 0.
+func (p POMDP) Update(b Belief, choice int, signal uint) (Belief, float64) {
+	for _, o := range p.outcomes(b, choice) {
+		if o.signal == signal {
+			return o.belief, o.prob
+		}
+	}
+	return nil, 0
+}
+
+func (p POMDP) observations(s uint) []Observation {
+	if len(p.Obs) <= int(s) || len(p.Obs[s]) == 0 {
+		return []Observation{{0, 1}}
+	}
+	return p.Obs[s]
+}
+
+// Result of one turn, conditioned on the signal received.
+type outcome struct {
+	signal uint
+	prob   float64
+	reward Value // Expected reward for the turn.
+	belief Belief
+}
+
+func (p POMDP) outcomes(b Belief, choice int) []outcome {
+	joint := map[uint]Belief{}
+	reward := map[uint]Value{}
+	signals := []uint{}
+	emit := func(s uint, w float64, r Value) {
+		for _, o := range p.observations(s) {
+			j, ok := joint[o.Signal]
+			if !ok {
+				j = make(Belief, len(p.MDP))
+				joint[o.Signal] = j
+				signals = append(signals, o.Signal)
+			}
+			j[s] += w * o.Prob
+			reward[o.Signal] += Value(w*o.Prob) * r
+		}
+	}
+	for s, w := range b {
+		if w == 0 {
+			continue
+		}
+		state := p.MDP[s]
+		switch {
+		case len(state.Action) == 0:
+			emit(uint(s), w, 0)
+		case state.Player == Nature:
+			for _, a := range state.Action {
+				emit(a.NextState, w*a.Prob, p.MDP[a.NextState].Reward)
+			}
+		default:
+			a := state.Action[choice]
+			emit(a.NextState, w, p.MDP[a.NextState].Reward)
+		}
+	}
+	sort.Slice(signals, func(i, j int) bool { return signals[i] < signals[j] })
+	out := []outcome{}
+	for _, o := range signals {
+		j := joint[o]
+		prob := 0.0
+		for _, w := range j {
+			prob += w
+		}
+		if prob == 0 {
+			continue
+		}
+		for i := range j {
+			j[i] /= prob
+		}
+		out = append(out, outcome{o, prob, reward[o] / Value(prob), j})
+	}
+	return out
+}
+
+/*
+BeliefMDP is the fully observable MDP whose states are the beliefs of a
+POMDP.  Choice[i][c] lists the possible results of making choice c in
+belief i, one edge per signal.  A belief with no choices is terminal.
+*/
+type BeliefMDP struct {
+	Belief []Belief
+	Choice [][][]BeliefEdge
+}
+
+type BeliefEdge struct {
+	Signal uint
+	Prob   float64
+	Reward Value // Expected reward, given the signal.
+	Next   uint
+}
+
+// Expand returns the belief MDP of the beliefs reachable from b0.  Beliefs
+// whose probabilities all agree to within tolerance are considered equal; with
+// a tolerance of 0 or less, only identical beliefs are.  If more than
+// maxBeliefs beliefs are reachable, Expand returns ErrTooManyBeliefs.
+func (p POMDP) Expand(b0 Belief, tolerance float64, maxBeliefs int) (BeliefMDP, error) {
+	m := BeliefMDP{}
+	index := map[string]uint{}
+	add := func(b Belief) (uint, bool) {
+		k := beliefKey(b, tolerance)
+		if i, ok := index[k]; ok {
+			return i, true
+		}
+		if len(m.Belief) >= maxBeliefs {
+			return 0, false
+		}
+		index[k] = uint(len(m.Belief))
+		m.Belief = append(m.Belief, b)
+		return index[k], true
+	}
+	add(b0)
+	for i := 0; i < len(m.Belief); i++ {
+		b := m.Belief[i]
+		choices := make([][]BeliefEdge, p.Choices(b))
+		for c := range choices {
+			for _, o := range p.outcomes(b, c) {
+				next, ok := add(o.belief)
+				if !ok {
+					return m, ErrTooManyBeliefs
+				}
+				choices[c] = append(choices[c], BeliefEdge{o.signal, o.prob, o.reward, next})
+			}
+		}
+		m.Choice = append(m.Choice, choices)
+	}
+	return m, nil
+}
+
+func beliefKey(b Belief, tolerance float64) string {
+	var k strings.Builder
+	for s, w := range b {
+		if tolerance <= 0 {
+			if w != 0 {
+				k.WriteString(strconv.Itoa(s))
+				k.WriteByte(':')
+				k.WriteString(strconv.FormatUint(math.Float64bits(w), 16))
+				k.WriteByte(' ')
+			}
+			continue
+		}
+		if q := math.Round(w / tolerance); q != 0 {
+			k.WriteString(strconv.Itoa(s))
+			k.WriteByte(':')
+			k.WriteString(strconv.FormatFloat(q, 'f', 0, 64))
+			k.WriteByte(' ')
+		}
+	}
+	return k.String()
+}
+
+// Values returns the value of each belief under the optimal policy, by value
+// iteration as in MDP.Values.
+func (m BeliefMDP) Values(discount, tolerance float64) []Value {
+	V, _ := m.Solve(discount, tolerance, nil)
+	return V
+}
+
+// Solve is like Values, but also returns statistics about the iteration.  If
+// progress is not nil, it is called after each iteration.
+func (m BeliefMDP) Solve(discount, tolerance float64, progress func(Stats)) ([]Value, Stats) {
+	γ := Value(discount)
+	return iterate(len(m.Belief), tolerance, progress, func(i int, V []Value) (Value, bool) {
+		if len(m.Choice[i]) == 0 {
+			return 0, false
+		}
+		max := negInf
+		for _, edges := range m.Choice[i] {
+			max = math.Max(max, float64(expect(edges, γ, V)))
+		}
+		return Value(max), true
+	})
+}
+
+// Policy returns the best choice in each belief given the values V returned
+// by Values, or -1 for terminal beliefs.
+func (m BeliefMDP) Policy(V []Value, discount float64) []int {
+	policy := make([]int, len(m.Belief))
+	for i, choices := range m.Choice {
+		policy[i] = -1
+		max := negInf
+		for c, edges := range choices {
+			if v := float64(expect(edges, Value(discount), V)); v > max {
+				max, policy[i] = v, c
+			}
+		}
+	}
+	return policy
+}
+
+func expect(edges []BeliefEdge, γ Value, V []Value) Value {
+	ev := Value(0.0)
+	for _, e := range edges {
+		ev += Value(e.Prob) * (e.Reward + γ*V[e.Next])
+	}
+	return ev
+}
+
+// AlphaVector gives the value, from each state, of a conditional plan that
+// begins with Choice.  States from which the plan can't be followed have value
+// -Inf.
+type AlphaVector struct {
+	Choice int
+	V      []Value
+}
+
+// Evaluate returns the value of belief b under the best of the alpha vectors,
+// and the choice that vector begins with.
+func Evaluate(alphas []AlphaVector, b Belief) (Value, int) {
+	max, choice := Value(negInf), -1
+	for _, α := range alphas {
+		if v := dot(b, α.V); v > max {
+			max, choice = v, α.Choice
+		}
+	}
+	return max, choice
+}
+
+func dot(b Belief, V []Value) Value {
+	sum := Value(0.0)
+	for s, w := range b {
+		if w != 0 {
+			sum += Value(w) * V[s]
+		}
+	}
+	return sum
+}
+
+// PointBased approximates the optimal value function with point-based value
+// iteration (Pineau, Gordon & Thrun 2003), backing up one alpha vector per
+// belief in points.  Good points are the beliefs reachable from the start,
+// e.g. those of a small Expand.  Iteration stops when the values of the
+// points change by no more than tolerance, or after maxIterations.
+func (p POMDP) PointBased(points []Belief, discount, tolerance float64, maxIterations int) []AlphaVector {
+	γ := Value(discount)
+	alphas := []AlphaVector{{0, make([]Value, len(p.MDP))}}
+	values := make([]Value, len(points))
+	for iter := 0; iter < maxIterations; iter++ {
+		// proj[c][k][o] is the vector of values for making choice c, getting
+		// signal o and then following the plan of alphas[k].
+		proj := map[int][]map[uint][]Value{}
+		next := make([]AlphaVector, len(points))
+		diff := false
+		for i, b := range points {
+			next[i] = AlphaVector{-1, make([]Value, len(p.MDP))}
+			best := Value(negInf)
+			if p.Choices(b) == 0 {
+				best = 0
+			}
+			for c := 0; c < p.Choices(b); c++ {
+				if _, ok := proj[c]; !ok {
+					for _, α := range alphas {
+						proj[c] = append(proj[c], p.project(α.V, c, γ))
+					}
+				}
+				α := p.backup(b, c, proj[c])
+				if v := dot(b, α.V); v > best {
+					best, next[i] = v, α
+				}
+			}
+			if math.Abs(float64(best-values[i])) > tolerance {
+				diff = true
+			}
+			values[i] = best
+		}
+		alphas = next
+		if !diff {
+			break
+		}
+	}
+	return alphas
+}
+
+// project returns, for each signal o, the vector of expected rewards for
+// making choice c, receiving o and then following the plan with values V.
+func (p POMDP) project(V []Value, c int, γ Value) map[uint][]Value {
+	g := map[uint][]Value{}
+	add := func(s int, next uint, prob float64) {
+		for _, o := range p.observations(next) {
+			w := prob * o.Prob
+			if w == 0 {
+				continue
+			}
+			if _, ok := g[o.Signal]; !ok {
+				g[o.Signal] = make([]Value, len(p.MDP))
+			}
+			v := p.MDP[next].Reward
+			if γ != 0 {
+				v += γ * V[next]
+			}
+			g[o.Signal][s] += Value(w) * v
+		}
+	}
+	for s, state := range p.MDP {
+		switch {
+		case len(state.Action) == 0:
+		case state.Player == Nature:
+			for _, a := range state.Action {
+				add(s, a.NextState, a.Prob)
+			}
+		case c < len(state.Action):
+			add(s, state.Action[c].NextState, 1)
+		}
+	}
+	return g
+}
+
+// backup returns the best alpha vector for belief b that begins with choice c,
+// given the projections of the current alpha vectors.
+func (p POMDP) backup(b Belief, c int, proj []map[uint][]Value) AlphaVector {
+	α := AlphaVector{c, make([]Value, len(p.MDP))}
+	best := map[uint][]Value{}
+	for _, g := range proj {
+		for o, v := range g {
+			if w, ok := best[o]; !ok || dot(b, v) > dot(b, w) {
+				best[o] = v
+			}
+		}
+	}
+	for _, v := range best {
+		for s := range α.V {
+			α.V[s] += v[s]
+		}
+	}
+	for s, state := range p.MDP {
+		if state.Player == Player1 && len(state.Action) > 0 && c >= len(state.Action) {
+			α.V[s] = Value(negInf)
+		}
+	}
+	return α
+}
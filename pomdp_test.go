@@ -0,0 +1,133 @@
+package mdp
+
+import (
+	"math"
+	"testing"
+)
+
+// Guess the color of a face-down card.  Peeking costs 0.1; a correct guess
+// wins 1.
+var guessCard = POMDP{
+	MDP: MDP{
+		State{Nature, 0, []Action{{1, 0.5}, {2, 0.5}}},      // 0: deal
+		State{Player1, 0, []Action{{5, 0}, {6, 0}, {3, 0}}}, // 1: red (say red, say black, peek)
+		State{Player1, 0, []Action{{6, 0}, {5, 0}, {4, 0}}}, // 2: black (say red, say black, peek)
+		State{Player1, -0.1, []Action{{5, 0}, {6, 0}}},      // 3: peeked at red
+		State{Player1, -0.1, []Action{{6, 0}, {5, 0}}},      // 4: peeked at black
+		State{Nature, 1, []Action{}},                        // 5: win
+		State{Nature, 0, []Action{}},                        // 6: lose
+	},
+	Obs: [][]Observation{
+		3: {{1, 1}}, // see red
+		4: {{2, 1}}, // see black
+	},
+}
+
+func TestUpdate(t *testing.T) {
+	cases := []struct {
+		b      Belief
+		choice int
+		signal uint
+		want   Belief
+		prob   float64
+	}{
+		{guessCard.Start(0), 0, 0, Belief{0, 0.5, 0.5, 0, 0, 0, 0}, 1},
+		{Belief{0, 0.5, 0.5, 0, 0, 0, 0}, 2, 1, Belief{0, 0, 0, 1, 0, 0, 0}, 0.5},
+		{Belief{0, 0.5, 0.5, 0, 0, 0, 0}, 0, 0, Belief{0, 0, 0, 0, 0, 0.5, 0.5}, 1},
+		{Belief{0, 0, 0, 1, 0, 0, 0}, 0, 0, Belief{0, 0, 0, 0, 0, 1, 0}, 1},
+		{Belief{0, 0.5, 0.5, 0, 0, 0, 0}, 0, 1, nil, 0},
+	}
+	for _, c := range cases {
+		got, prob := guessCard.Update(c.b, c.choice, c.signal)
+		if len(got) != len(c.want) || prob != c.prob {
+			t.Errorf("Update(%v, %d, %d)=%v, %v; want %v, %v", c.b, c.choice, c.signal, got, prob, c.want, c.prob)
+			continue
+		}
+		for s := range got {
+			if got[s] != c.want[s] {
+				t.Errorf("Update(%v, %d, %d)=%v, %v; want %v, %v", c.b, c.choice, c.signal, got, prob, c.want, c.prob)
+				break
+			}
+		}
+	}
+}
+
+func TestPOMDPValues(t *testing.T) {
+	tolerance := 1e-9
+	// Bus Ticket Roulette (see TestValues), with every state visible.
+	roulette := POMDP{MDP: MDP{
+		State{Nature, 0, []Action{}},
+		State{Player1, 0, []Action{{5, 0}}},
+		State{Player1, 0, []Action{{6, 0}, {7, 0}}},
+		State{Player1, 0, []Action{{8, 0}}},
+		State{Nature, 1, []Action{}},
+		State{Nature, 0, []Action{{2, 18.0 / 38}, {0, 20.0 / 38}}},
+		State{Nature, 0, []Action{{3, 18.0 / 38}, {1, 20.0 / 38}}},
+		State{Nature, 0, []Action{{4, 18.0 / 38}, {0, 20.0 / 38}}},
+		State{Nature, 0, []Action{{4, 18.0 / 38}, {2, 20.0 / 38}}},
+	}}
+	for s := range roulette.MDP {
+		roulette.Obs = append(roulette.Obs, []Observation{{uint(s), 1}})
+	}
+	cases := []struct {
+		p      POMDP
+		start  uint
+		want   Value
+		choice int
+	}{
+		{guessCard, 0, 0.9, 0},
+		{roulette, 2, 18.0 / 38, 1},
+		{roulette, 3, 18.0/38 + (18.0*20.0)/(38*38), 0},
+	}
+	for _, c := range cases {
+		m, err := c.p.Expand(c.p.Start(c.start), tolerance, 100)
+		if err != nil {
+			t.Errorf("Expand(%d): %v", c.start, err)
+			continue
+		}
+		V := m.Values(1.0, tolerance)
+		if math.Abs(float64(V[0]-c.want)) > tolerance {
+			t.Errorf("Expand(%d).Values() V[0]: got %v; want %v", c.start, V[0], c.want)
+		}
+		if c.p.Choices(m.Belief[0]) > 1 {
+			if got := m.Policy(V, 1.0)[0]; got != c.choice {
+				t.Errorf("Expand(%d).Policy()[0]: got %v; want %v", c.start, got, c.choice)
+			}
+		}
+		alphas := c.p.PointBased(m.Belief, 1.0, tolerance, 1000)
+		if got, _ := Evaluate(alphas, m.Belief[0]); math.Abs(float64(got-c.want)) > tolerance {
+			t.Errorf("PointBased(%d) at start: got %v; want %v", c.start, got, c.want)
+		}
+	}
+}
+
+func TestBeliefKey(t *testing.T) {
+	a, b := Belief{0.5, 0.5, 0}, Belief{0.5 + 1e-12, 0.5 - 1e-12, 0}
+	cases := []struct {
+		tolerance float64
+		same      bool
+	}{
+		{1e-9, true},
+		{0, false},
+		{-1, false},
+	}
+	for _, c := range cases {
+		ka, kb := beliefKey(a, c.tolerance), beliefKey(b, c.tolerance)
+		if (ka == kb) != c.same || ka == "" {
+			t.Errorf("beliefKey(%v, %v)=%q, beliefKey(%v)=%q; want same %v", a, c.tolerance, ka, b, kb, c.same)
+		}
+	}
+	m, err := guessCard.Expand(guessCard.Start(0), 0, 100)
+	if err != nil {
+		t.Fatalf("Expand(tolerance=0): %v", err)
+	}
+	if V := m.Values(1.0, 1e-9); math.Abs(float64(V[0]-0.9)) > 1e-9 {
+		t.Errorf("Expand(tolerance=0).Values() V[0]: got %v; want 0.9", V[0])
+	}
+}
+
+func TestExpandTooManyBeliefs(t *testing.T) {
+	if _, err := guessCard.Expand(guessCard.Start(0), 1e-9, 3); err != ErrTooManyBeliefs {
+		t.Errorf("Expand(maxBeliefs=3): got %v; want %v", err, ErrTooManyBeliefs)
+	}
+}
@@ -0,0 +1,239 @@
+package mdp
+
+import (
+	"math"
+)
+
+// Policy returns the index of the best action of each player state, given the
+// values V returned by Values, or -1 for nature states and states with no
+// actions.
+func (states MDP) Policy(V []Value, discount float64) []int {
+	γ := Value(discount)
+	policy := make([]int, len(states))
+	for i, state := range states {
+		policy[i] = -1
+		if state.Player != Player1 {
+			continue
+		}
+		max := negInf
+		for a, action := range state.Action {
+			s := action.NextState
+			if v := float64(states[s].Reward + γ*V[s]); v > max {
+				max, policy[i] = v, a
+			}
+		}
+	}
+	return policy
+}
+
+// PolicyValues returns the value of each state when the player follows the
+// given policy, by value iteration as in Values.
+func (states MDP) PolicyValues(policy []int, discount, tolerance float64) []Value {
+	γ := Value(discount)
+	V, _ := iterate(len(states), tolerance, nil, func(i int, V []Value) (Value, bool) {
+		state := states[i]
+		if len(state.Action) == 0 {
+			return 0, false
+		}
+		if state.Player == Player1 {
+			s := state.Action[policy[i]].NextState
+			return states[s].Reward + γ*V[s], true
+		}
+		ev := Value(0.0)
+		for _, action := range state.Action {
+			s := action.NextState
+			ev += Value(action.Prob) * (states[s].Reward + γ*V[s])
+		}
+		return ev, true
+	})
+	return V
+}
+
+// Visits returns the expected discounted number of times the token is on each
+// state, starting from state start and following the given policy.
+func (states MDP) Visits(start uint, policy []int, discount, tolerance float64) []Value {
+	γ := Value(discount)
+	var y []Value
+	next := make([]Value, len(states))
+	next[start] = 1
+	for diff := true; diff; {
+		diff = false
+		y, next = next, make([]Value, len(states))
+		next[start] = 1
+		for i, state := range states {
+			if y[i] == 0 || len(state.Action) == 0 {
+				continue
+			}
+			switch state.Player {
+			case Nature:
+				for _, action := range state.Action {
+					next[action.NextState] += γ * Value(action.Prob) * y[i]
+				}
+			case Player1:
+				next[state.Action[policy[i]].NextState] += γ * y[i]
+			}
+		}
+		for i := range y {
+			if math.Abs(float64(next[i]-y[i])) > tolerance {
+				diff = true
+			}
+		}
+	}
+	return next
+}
+
+// Sensitivity holds the partial derivatives of the value of one state with
+// respect to each parameter of an MDP, holding the policy fixed.
+// Probabilities are perturbed one at a time, without renormalizing; to move
+// probability from one action to another, combine their derivatives.
+type Sensitivity struct {
+	Reward []Value   // Reward[k] is the derivative by states[k].Reward.
+	Prob   [][]Value // Prob[i][a] is the derivative by states[i].Action[a].Prob.
+}
+
+// Sensitivity returns the derivatives of V[start] with respect to every
+// reward and nature probability, where V and policy are the values and policy
+// returned by Values and Policy.  Since a small enough change to the
+// parameters doesn't change an optimal policy (see PolicyRange), these are
+// also the derivatives of the optimal value, except where two actions tie.
+func (states MDP) Sensitivity(start uint, V []Value, policy []int, discount, tolerance float64) Sensitivity {
+	γ := Value(discount)
+	y := states.Visits(start, policy, discount, tolerance)
+	sens := Sensitivity{make([]Value, len(states)), make([][]Value, len(states))}
+	for i, state := range states {
+		sens.Prob[i] = make([]Value, len(state.Action))
+		if y[i] == 0 || len(state.Action) == 0 {
+			continue
+		}
+		switch state.Player {
+		case Nature:
+			for a, action := range state.Action {
+				s := action.NextState
+				sens.Prob[i][a] = y[i] * (states[s].Reward + γ*V[s])
+				sens.Reward[s] += y[i] * Value(action.Prob)
+			}
+		case Player1:
+			sens.Reward[state.Action[policy[i]].NextState] += y[i]
+		}
+	}
+	return sens
+}
+
+// Perturbation is a direction in which to change the parameters of an MDP.
+// The MDP perturbed by θ has each reward increased by θ·Reward[state] and
+// each probability increased by θ·Prob[Edge{state, action}].
+type Perturbation struct {
+	Reward map[uint]Value
+	Prob   map[Edge]float64
+}
+
+// Edge identifies an action of a state.
+type Edge struct {
+	State, Action uint
+}
+
+// Derivative returns the derivative of V[start] in the direction d.
+func (sens Sensitivity) Derivative(d Perturbation) Value {
+	sum := Value(0.0)
+	for s, r := range d.Reward {
+		sum += r * sens.Reward[s]
+	}
+	for e, p := range d.Prob {
+		sum += Value(p) * sens.Prob[e.State][e.Action]
+	}
+	return sum
+}
+
+// Perturb returns a copy of the MDP perturbed by θ in the direction d.
+func (states MDP) Perturb(d Perturbation, θ float64) MDP {
+	perturbed := make(MDP, len(states))
+	for i, state := range states {
+		perturbed[i] = State{state.Player, state.Reward + Value(θ)*d.Reward[uint(i)], append([]Action{}, state.Action...)}
+		for a := range state.Action {
+			perturbed[i].Action[a].Prob += θ * d.Prob[Edge{uint(i), uint(a)}]
+		}
+	}
+	return perturbed
+}
+
+// PolicyRange returns the interval of θ over which policy remains optimal
+// for the MDP perturbed by θ in the direction d, to within tolerance.  The
+// interval is limited to θ for which every probability stays within [0, 1];
+// an infinite bound means the policy remains optimal however far the rewards
+// change.  If policy isn't optimal to begin with, PolicyRange returns NaNs.
+func (states MDP) PolicyRange(policy []int, d Perturbation, discount, tolerance float64) (lo, hi float64) {
+	optimal := func(θ float64) bool {
+		return states.Perturb(d, θ).isOptimal(policy, discount, tolerance)
+	}
+	if !optimal(0) {
+		return math.NaN(), math.NaN()
+	}
+	lo, hi = math.Inf(-1), math.Inf(1)
+	for e, dp := range d.Prob {
+		p := states[e.State].Action[e.Action].Prob
+		switch {
+		case dp > 0:
+			lo, hi = math.Max(lo, -p/dp), math.Min(hi, (1-p)/dp)
+		case dp < 0:
+			lo, hi = math.Max(lo, (1-p)/dp), math.Min(hi, -p/dp)
+		}
+	}
+	return -edge(func(θ float64) bool { return optimal(-θ) }, -lo, tolerance),
+		edge(optimal, hi, tolerance)
+}
+
+// edge returns the largest θ in [0, limit] such that ok(θ) holds on all of
+// [0, θ].  It checks 64 evenly spaced θ (or doubling θ, if limit is infinite)
+// to find the first bad one, then bisects.
+func edge(ok func(float64) bool, limit, tolerance float64) float64 {
+	good, bad := 0.0, limit
+	next := func(θ float64) float64 { return θ + limit/64 }
+	if math.IsInf(limit, 1) {
+		next = func(θ float64) float64 { return math.Max(1, 2*θ) }
+	}
+	for θ := next(0); ; θ = next(θ) {
+		if θ > 1e15 {
+			return limit
+		}
+		if θ >= limit {
+			if ok(limit) {
+				return limit
+			}
+			break
+		}
+		if !ok(θ) {
+			bad = θ
+			break
+		}
+		good = θ
+	}
+	for bad-good > tolerance {
+		mid := (good + bad) / 2
+		if ok(mid) {
+			good = mid
+		} else {
+			bad = mid
+		}
+	}
+	return good
+}
+
+// isOptimal reports whether no player state has an action better than the
+// one the policy chooses by more than tolerance.
+func (states MDP) isOptimal(policy []int, discount, tolerance float64) bool {
+	γ := Value(discount)
+	V := states.PolicyValues(policy, discount, tolerance/10)
+	for i, state := range states {
+		if state.Player != Player1 || len(state.Action) == 0 {
+			continue
+		}
+		chosen := state.Action[policy[i]].NextState
+		for _, action := range state.Action {
+			s := action.NextState
+			if states[s].Reward+γ*V[s] > states[chosen].Reward+γ*V[chosen]+Value(tolerance) {
+				return false
+			}
+		}
+	}
+	return true
+}
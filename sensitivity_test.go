@@ -0,0 +1,70 @@
+package mdp
+
+import (
+	"math"
+	"testing"
+)
+
+// Bus Ticket Roulette (see TestValues).
+var busTicket = MDP{
+	State{Nature, 0, []Action{}},                               // 0: $0 (lose)
+	State{Player1, 0, []Action{{5, 0}}},                        // 1: $1
+	State{Player1, 0, []Action{{6, 0}, {7, 0}}},                // 2: $2
+	State{Player1, 0, []Action{{8, 0}}},                        // 3: $3
+	State{Nature, 1, []Action{}},                               // 4: $4+ (win)
+	State{Nature, 0, []Action{{2, 18.0 / 38}, {0, 20.0 / 38}}}, // 5: have $1, bet $1 on red
+	State{Nature, 0, []Action{{3, 18.0 / 38}, {1, 20.0 / 38}}}, // 6: have $2, bet $1 on red
+	State{Nature, 0, []Action{{4, 18.0 / 38}, {0, 20.0 / 38}}}, // 7: have $2, bet $2 on red
+	State{Nature, 0, []Action{{4, 18.0 / 38}, {2, 20.0 / 38}}}, // 8: have $3, bet $1 on red
+}
+
+// Moves probability from black to red on every spin.
+var redder = Perturbation{Prob: map[Edge]float64{
+	{5, 0}: 1, {5, 1}: -1,
+	{6, 0}: 1, {6, 1}: -1,
+	{7, 0}: 1, {7, 1}: -1,
+	{8, 0}: 1, {8, 1}: -1,
+}}
+
+func TestSensitivity(t *testing.T) {
+	tolerance := 1e-12
+	p := 18.0 / 38
+	V := busTicket.Values(1.0, tolerance)
+	policy := busTicket.Policy(V, 1.0)
+	if policy[2] != 1 {
+		t.Fatalf("Policy()[2]=%d; want 1 (bold play)", policy[2])
+	}
+	cases := []struct {
+		start uint
+		d     Perturbation
+		want  Value
+	}{
+		{2, redder, 1},                                        // V[2] = p
+		{1, redder, Value(2 * p)},                             // V[1] = p²
+		{3, redder, Value(2 - 2*p)},                           // V[3] = p + (1-p)p
+		{3, Perturbation{Reward: map[uint]Value{4: 1}}, V[3]}, // P(win)
+		{3, Perturbation{Reward: map[uint]Value{2: 1}}, Value(1 - p)},
+	}
+	for _, c := range cases {
+		sens := busTicket.Sensitivity(c.start, V, policy, 1.0, tolerance)
+		if got := sens.Derivative(c.d); math.Abs(float64(got-c.want)) > 1e-9 {
+			t.Errorf("Sensitivity(%d).Derivative(%v)=%v; want %v", c.start, c.d, got, c.want)
+		}
+	}
+}
+
+func TestPolicyRange(t *testing.T) {
+	tolerance := 1e-9
+	V := busTicket.Values(1.0, tolerance)
+	policy := busTicket.Policy(V, 1.0)
+	// Bold play stays optimal until red is as likely as black.
+	lo, hi := busTicket.PolicyRange(policy, redder, 1.0, tolerance)
+	if math.Abs(lo+18.0/38) > 1e-6 || math.Abs(hi-1.0/38) > 1e-6 {
+		t.Errorf("PolicyRange(redder)=[%v, %v]; want [%v, %v]", lo, hi, -18.0/38, 1.0/38)
+	}
+	// Winning $3 instead of $4 never changes the policy.
+	lo, hi = busTicket.PolicyRange(policy, Perturbation{Reward: map[uint]Value{4: 1}}, 1.0, tolerance)
+	if math.Abs(lo+1) > 1e-6 || !math.IsInf(hi, 1) {
+		t.Errorf("PolicyRange(win reward)=[%v, %v]; want [-1, +Inf]", lo, hi)
+	}
+}